@@ -0,0 +1,70 @@
+package httptesting
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Benchmark executes the current request b.N times and reports allocations and the response size as bytes per operation.
+// The request is prepared once and its body and the response recorder are reused between iterations, so only the handler
+// is measured. The response to the first, untimed, execution is stored as the previous response and can be asserted the same as Execute.
+// A panic in any iteration fails the benchmark with the request and stack trace, the same as Execute:
+//
+//	func BenchmarkGetTodos(b *testing.B) {
+//		ht := httptesting.New(b, handler)
+//		ht.Get("/todos")
+//		ht.Benchmark(b)
+//		ht.AssertStatusCode(http.StatusOK)
+//	}
+func (ht *Httptester) Benchmark(b *testing.B) {
+	ht.t.Helper()
+	expectPanic := ht.expectPanic
+	req, requestBody, release := ht.prepareRequest()
+	defer release()
+	body := bytes.NewReader(requestBody)
+	requestReader := io.NopCloser(body)
+	req.Body = requestReader
+
+	response := httptest.NewRecorder()
+	start := time.Now()
+	p := ht.serve(response, req)
+	duration := time.Since(start)
+
+	ht.requestExecuted = true
+	ht.state.Response = response.Result()
+	ht.state.Request = nil
+	ht.recordExchange(&Exchange{
+		Method:         req.Method,
+		URL:            req.URL.String(),
+		RequestHeader:  req.Header.Clone(),
+		RequestBody:    requestBody,
+		StatusCode:     response.Code,
+		ResponseHeader: ht.state.Response.Header.Clone(),
+		ResponseBody:   response.Body.Bytes(),
+		Start:          start,
+		Duration:       duration,
+	})
+	ht.handlePanic(p, expectPanic, req, requestBody)
+	ht.runResponseHooks(ht.exchange)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(response.Body.Len()))
+	header := make(map[string][]string)
+	var buf bytes.Buffer
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for key := range header {
+			delete(header, key)
+		}
+		buf.Reset()
+		*response = httptest.ResponseRecorder{HeaderMap: header, Body: &buf, Code: 200}
+		body.Reset(requestBody)
+		req.Body = requestReader
+		if p := ht.serve(response, req); p != nil {
+			ht.handlePanic(p, expectPanic, req, requestBody)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package httptesting
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// echoHandler responds with the request body
+func echoHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, err = w.Write(body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+}
+
+func BenchmarkEcho(b *testing.B) {
+	tester := New(b, echoHandler())
+	tester.Post("/echo", strings.NewReader("Ok"))
+	tester.Benchmark(b)
+	tester.AssertBody([]byte("Ok"))
+}
+
+func TestBenchmark(t *testing.T) {
+	t.Parallel()
+	t.Run("reuses the request body on every iteration", func(t *testing.T) {
+		t.Parallel()
+		var calls, mismatches int
+		handler := echoHandler()
+		result := testing.Benchmark(func(b *testing.B) {
+			tester := New(b, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				body, err := io.ReadAll(r.Body)
+				if err != nil || string(body) != "hello" {
+					mismatches++
+				}
+				r.Body = io.NopCloser(strings.NewReader(string(body)))
+				handler.ServeHTTP(w, r)
+			}))
+			tester.Post("/echo", strings.NewReader("hello"))
+			tester.Benchmark(b)
+			tester.AssertStatusCode(http.StatusOK)
+			tester.AssertHeader("Content-Type", "text/plain")
+			tester.AssertBody([]byte("hello"))
+		})
+		if result.N == 0 || calls < result.N {
+			t.Fatalf("Expected the handler to be called at least %d times; got %d", result.N, calls)
+		}
+		if mismatches > 0 {
+			t.Fatalf("Expected request body %q on every iteration; got %d mismatches", "hello", mismatches)
+		}
+		if result.Bytes != 5 {
+			t.Fatalf("Expected 5 bytes per operation; got %d", result.Bytes)
+		}
+	})
+	t.Run("panic fails the benchmark", func(t *testing.T) {
+		t.Parallel()
+		var tester *Httptester
+		var calls int
+		result := testing.Benchmark(func(b *testing.B) {
+			calls = 0
+			tester = New(b, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls > 1 {
+					panic("boom")
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			tester.Get("/")
+			tester.Benchmark(b)
+		})
+		if result.N != 0 {
+			t.Fatalf("Expected the benchmark to fail; ran %d iterations", result.N)
+		}
+		if tester.state.Panic == nil || tester.state.Panic.Value != "boom" || len(tester.state.Panic.Stack) == 0 {
+			t.Fatalf("Expected the panic to be recorded with its stack; got %v", tester.state.Panic)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package httptesting
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// Case a single request in a table-driven test and the assertions made on its response.
+// Path, Headers, bodies and expected values may reference values saved by earlier cases of a shared httptester with ${NAME}
+type Case struct {
+	// Name name of the subtest
+	Name string
+
+	// Method http method of the request. Defaults to GET
+	Method string
+
+	// Path path and query of the request
+	Path string
+
+	// Headers headers added to the request
+	Headers map[string]string
+
+	// Body raw request body
+	Body string
+
+	// JSON request body encoded as JSON when not nil. Sets Content-Type to application/json unless set in Headers
+	JSON any
+
+	// Status expected status code, not asserted when zero
+	Status int
+
+	// ResponseHeaders expected header values of the response
+	ResponseHeaders map[string]string
+
+	// ResponseBody expected raw response body, not asserted when empty
+	ResponseBody string
+
+	// ResponseJSON expected response body when not nil, compared semantically with the decoded response body
+	ResponseJSON any
+
+	// Save maps value names to dotted paths in the JSON response body, stored in State.Values for later cases
+	Save map[string]string
+
+	// Setup called before the request is executed. Headers and cookies added to the current request are kept
+	Setup func(t *testing.T, ht *Httptester)
+
+	// Check additional assertions made on the response
+	Check func(t *testing.T, ht *Httptester)
+
+	// Teardown called when the subtest and all its subtests complete, even if it failed
+	Teardown func(t *testing.T, ht *Httptester)
+}
+
+// CaseOptions options for RunCases
+type CaseOptions struct {
+	// Shared executes every case in order on the same httptester so cookies and saved values are chained between cases.
+	// By default every case gets a new httptester
+	Shared bool
+
+	// Parallel runs the cases in parallel with t.Parallel. Ignored when Shared is set
+	Parallel bool
+
+	// Setup called before the Setup of every case
+	Setup func(t *testing.T, ht *Httptester)
+
+	// Teardown called after the Teardown of every case
+	Teardown func(t *testing.T, ht *Httptester)
+}
+
+// RunCases executes each case as a subtest of t against h
+func RunCases(t *testing.T, h http.Handler, cases []Case, opts CaseOptions) {
+	t.Helper()
+	var shared *Httptester
+	if opts.Shared {
+		shared = New(t, h)
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			ht := shared
+			if ht == nil {
+				if opts.Parallel {
+					t.Parallel()
+				}
+				ht = New(t, h)
+			} else {
+				ht.setT(t)
+			}
+			if opts.Teardown != nil {
+				t.Cleanup(func() { opts.Teardown(t, ht) })
+			}
+			if c.Teardown != nil {
+				t.Cleanup(func() { c.Teardown(t, ht) })
+			}
+			if opts.Setup != nil {
+				opts.Setup(t, ht)
+			}
+			if c.Setup != nil {
+				c.Setup(t, ht)
+			}
+			ht.runStep(c.step(t), nil)
+			if c.Check != nil {
+				c.Check(t, ht)
+			}
+		})
+		if shared != nil {
+			shared.setT(t)
+		}
+	}
+}
+
+// setT helper function to change the TestingT failures are reported to, keeping the Reporter wrapper if one is set
+func (ht *Httptester) setT(t *testing.T) {
+	if reporting, ok := ht.t.(*reportingT); ok {
+		reporting.TestingT = t
+		return
+	}
+	ht.t = t
+}
+
+// step helper function to convert a case to a scenario step
+func (c Case) step(t *testing.T) Step {
+	step := Step{
+		Method:  c.Method,
+		Path:    c.Path,
+		Headers: c.Headers,
+		Body:    c.Body,
+		Save:    c.Save,
+		Expect:  Expect{Status: c.Status, Headers: c.ResponseHeaders},
+	}
+	var err error
+	if c.JSON != nil {
+		if step.JSON, err = json.Marshal(c.JSON); err != nil {
+			t.Fatalf("Error encoding request body: %s", err.Error())
+		}
+	}
+	if c.ResponseBody != "" {
+		step.Expect.Body = &c.ResponseBody
+	}
+	if c.ResponseJSON != nil {
+		if step.Expect.JSON, err = json.Marshal(c.ResponseJSON); err != nil {
+			t.Fatalf("Error encoding expected response body: %s", err.Error())
+		}
+	}
+	return step
+}
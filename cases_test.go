@@ -0,0 +1,97 @@
+package httptesting
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunCases(t *testing.T) {
+	t.Parallel()
+	t.Run("fresh httptester per case", func(t *testing.T) {
+		t.Parallel()
+		var setups, teardowns int32
+		RunCases(t, todoHandler(), []Case{
+			{
+				Name:            "create todo",
+				Method:          http.MethodPost,
+				Path:            "/todo",
+				JSON:            map[string]string{"name": "Get Groceries"},
+				Status:          http.StatusCreated,
+				ResponseHeaders: map[string]string{"Content-Type": "application/json"},
+				ResponseJSON:    map[string]any{"id": 42, "name": "Get Groceries"},
+			},
+			{
+				Name:   "unauthorized",
+				Method: http.MethodPost,
+				Path:   "/todo",
+				Status: http.StatusUnauthorized,
+				Setup: func(t *testing.T, ht *Httptester) {
+					ht.AddHeader("Authorization", "Bearer wrong")
+				},
+			},
+			{
+				Name:         "get todo",
+				Path:         "/todo/42",
+				Status:       http.StatusOK,
+				ResponseBody: "Get Groceries",
+				Check: func(t *testing.T, ht *Httptester) {
+					ht.AssertHeader("Content-Type", "text/plain; charset=utf-8")
+				},
+			},
+		}, CaseOptions{
+			Parallel: true,
+			Setup: func(t *testing.T, ht *Httptester) {
+				atomic.AddInt32(&setups, 1)
+				ht.AddHeader("Authorization", "Bearer secret")
+			},
+			Teardown: func(t *testing.T, ht *Httptester) {
+				atomic.AddInt32(&teardowns, 1)
+			},
+		})
+		t.Cleanup(func() {
+			if setups != 3 || teardowns != 3 {
+				t.Errorf("Expected 3 setups and teardowns; got %d and %d", setups, teardowns)
+			}
+		})
+	})
+
+	t.Run("shared httptester chains saved values", func(t *testing.T) {
+		t.Parallel()
+		RunCases(t, todoHandler(), []Case{
+			{
+				Name:    "create todo",
+				Method:  http.MethodPost,
+				Path:    "/todo",
+				Headers: map[string]string{"Authorization": "Bearer secret"},
+				Status:  http.StatusCreated,
+				Save:    map[string]string{"id": "id"},
+			},
+			{
+				Name:         "get todo",
+				Path:         "/todo/${id}",
+				Status:       http.StatusOK,
+				ResponseBody: "Get Groceries",
+			},
+		}, CaseOptions{Shared: true})
+	})
+
+	t.Run("shared httptester reports to the parent test after the cases", func(t *testing.T) {
+		t.Parallel()
+		var shared *Httptester
+		RunCases(t, todoHandler(), []Case{
+			{Name: "unauthorized", Method: http.MethodPost, Path: "/todo", Status: http.StatusUnauthorized},
+		}, CaseOptions{
+			Shared: true,
+			Setup:  func(t *testing.T, ht *Httptester) { shared = ht },
+			Teardown: func(t *testing.T, ht *Httptester) {
+				if ht.t != t {
+					t.Errorf("Expected teardown to report to the case subtest")
+				}
+			},
+		})
+		if shared.t != t {
+			t.Fatalf("Expected the shared httptester to report to the parent test; got %s", shared.t.Name())
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package httptesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Cassette recorded exchanges that can be saved to a file and replayed later to catch unintended changes in behavior
+type Cassette struct {
+	mu        sync.Mutex
+	exchanges []*Exchange
+}
+
+// cassetteFile JSON representation of a Cassette
+type cassetteFile struct {
+	Exchanges []*Exchange `json:"exchanges"`
+}
+
+// ReplayOptions configures which parts of a response are ignored when replaying a Cassette
+type ReplayOptions struct {
+	// IgnoreHeaders response headers that are not compared, e.g. Date or Set-Cookie
+	IgnoreHeaders []string
+
+	// IgnoreFields dotted paths of fields in JSON response bodies that are not compared, e.g. "id" or "items.*.createdAt".
+	// A * segment matches any object key or array index
+	IgnoreFields []string
+}
+
+// NewCassette returns a new empty Cassette
+func NewCassette() *Cassette {
+	return &Cassette{}
+}
+
+// LoadCassette reads a Cassette saved with Save
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file cassetteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	return &Cassette{exchanges: file.Exchanges}, nil
+}
+
+// Save writes the recorded exchanges to path as JSON
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	file := cassetteFile{Exchanges: c.exchanges}
+	data, err := json.MarshalIndent(file, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Exchanges returns the recorded exchanges
+func (c *Cassette) Exchanges() []*Exchange {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Exchange(nil), c.exchanges...)
+}
+
+// add records an exchange
+func (c *Cassette) add(e *Exchange) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.exchanges = append(c.exchanges, e)
+}
+
+// Record records every subsequent request executed by the httptester in c
+func (ht *Httptester) Record(c *Cassette) {
+	ht.cassette = c
+}
+
+// Replay re-executes every request recorded in c in order and asserts the new responses match the recorded ones.
+// Cookies are chained by the httptester instead of being replayed from the recording.
+// All differences are reported together once every request has been replayed
+func (ht *Httptester) Replay(c *Cassette, opts ReplayOptions) {
+	ht.t.Helper()
+	ignoredHeaders := make(map[string]bool)
+	for _, key := range opts.IgnoreHeaders {
+		ignoredHeaders[http.CanonicalHeaderKey(key)] = true
+	}
+
+	var failures []string
+	for i, recorded := range c.Exchanges() {
+		ht.NewRequest(recorded.Method, recorded.URL, bytes.NewReader(recorded.RequestBody))
+		for key, values := range recorded.RequestHeader {
+			if http.CanonicalHeaderKey(key) == "Cookie" {
+				continue
+			}
+			for _, value := range values {
+				ht.getRequest().Header.Add(key, value)
+			}
+		}
+		ht.Execute()
+
+		diffs := diffExchange(recorded, ht.exchange, ignoredHeaders, opts.IgnoreFields)
+		if len(diffs) > 0 {
+			failures = append(failures, fmt.Sprintf("exchange %d %s %s:\n\t%s", i+1, recorded.Method, recorded.URL, strings.Join(diffs, "\n\t")))
+		}
+	}
+	if len(failures) > 0 {
+		ht.t.Fatalf("Replayed responses differ from cassette:\n%s", strings.Join(failures, "\n"))
+	}
+}
+
+// diffExchange returns the differences between the response of a recorded exchange and a replayed one
+func diffExchange(recorded, replayed *Exchange, ignoredHeaders map[string]bool, ignoredFields []string) []string {
+	var diffs []string
+	if recorded.StatusCode != replayed.StatusCode {
+		diffs = append(diffs, fmt.Sprintf("status: expected %d; got %d", recorded.StatusCode, replayed.StatusCode))
+	}
+
+	keys := make(map[string]bool)
+	for key := range recorded.ResponseHeader {
+		keys[http.CanonicalHeaderKey(key)] = true
+	}
+	for key := range replayed.ResponseHeader {
+		keys[http.CanonicalHeaderKey(key)] = true
+	}
+	for _, key := range sortedKeys(keys) {
+		if ignoredHeaders[key] {
+			continue
+		}
+		want, got := recorded.ResponseHeader.Values(key), replayed.ResponseHeader.Values(key)
+		if !reflect.DeepEqual(want, got) {
+			diffs = append(diffs, fmt.Sprintf("header %s: expected %q; got %q", key, want, got))
+		}
+	}
+
+	var want, got any
+	if json.Unmarshal(recorded.ResponseBody, &want) == nil && json.Unmarshal(replayed.ResponseBody, &got) == nil {
+		diffJSON(nil, want, got, ignoredFields, &diffs)
+	} else if !bytes.Equal(recorded.ResponseBody, replayed.ResponseBody) {
+		diffs = append(diffs, fmt.Sprintf("body: expected %q; got %q", recorded.ResponseBody, replayed.ResponseBody))
+	}
+	return diffs
+}
+
+// diffJSON appends the differences between two decoded JSON documents to diffs, skipping ignored fields
+func diffJSON(path []string, want, got any, ignoredFields []string, diffs *[]string) {
+	if fieldIgnored(path, ignoredFields) {
+		return
+	}
+	name := strings.Join(path, ".")
+	if name == "" {
+		name = "body"
+	}
+
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make(map[string]bool)
+		for key := range w {
+			keys[key] = true
+		}
+		for key := range g {
+			keys[key] = true
+		}
+		for _, key := range sortedKeys(keys) {
+			child := append(append([]string(nil), path...), key)
+			wantValue, wantOk := w[key]
+			gotValue, gotOk := g[key]
+			switch {
+			case fieldIgnored(child, ignoredFields):
+			case !gotOk:
+				*diffs = append(*diffs, fmt.Sprintf("%s: missing", strings.Join(child, ".")))
+			case !wantOk:
+				*diffs = append(*diffs, fmt.Sprintf("%s: unexpected value %s", strings.Join(child, "."), jsonString(gotValue)))
+			default:
+				diffJSON(child, wantValue, gotValue, ignoredFields, diffs)
+			}
+		}
+		return
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(w) != len(g) {
+			break
+		}
+		for i := range w {
+			diffJSON(append(append([]string(nil), path...), fmt.Sprint(i)), w[i], g[i], ignoredFields, diffs)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		*diffs = append(*diffs, fmt.Sprintf("%s: expected %s; got %s", name, jsonString(want), jsonString(got)))
+	}
+}
+
+// fieldIgnored returns true if path matches any of the ignored dotted paths
+func fieldIgnored(path []string, ignoredFields []string) bool {
+	for _, field := range ignoredFields {
+		segments := strings.Split(field, ".")
+		if len(segments) != len(path) {
+			continue
+		}
+		matched := true
+		for i, segment := range segments {
+			if segment != "*" && segment != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonString helper function to format a decoded JSON value in failure messages
+func jsonString(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,125 @@
+package httptesting
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+func counterHandler(version string) http.Handler {
+	var count int64
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&count, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", fmt.Sprint(n))
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		_, err := fmt.Fprintf(w, `{"id": %d, "version": %q, "items": [{"name": "a", "createdAt": %d}]}`, n, version, n*1000)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+}
+
+func recordCassette(t *testing.T) string {
+	t.Helper()
+	cassette := NewCassette()
+	tester := New(t, counterHandler("v1"))
+	tester.Record(cassette)
+	tester.Post("/todo", strings.NewReader(`{"name": "a"}`))
+	tester.AddHeader("Content-Type", "application/json")
+	tester.Execute()
+	tester.Get("/todo/1")
+	tester.Execute()
+
+	if len(cassette.Exchanges()) != 2 {
+		t.Fatalf("Expected 2 recorded exchanges; got %d", len(cassette.Exchanges()))
+	}
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := cassette.Save(path); err != nil {
+		t.Fatalf("Error saving cassette: %s", err.Error())
+	}
+	return path
+}
+
+func TestCassette(t *testing.T) {
+	t.Parallel()
+	t.Run("saves and loads exchanges", func(t *testing.T) {
+		t.Parallel()
+		cassette, err := LoadCassette(recordCassette(t))
+		if err != nil {
+			t.Fatalf("Error loading cassette: %s", err.Error())
+		}
+		exchanges := cassette.Exchanges()
+		if len(exchanges) != 2 {
+			t.Fatalf("Expected 2 exchanges; got %d", len(exchanges))
+		}
+		if exchanges[0].Method != http.MethodPost || string(exchanges[0].RequestBody) != `{"name": "a"}` {
+			t.Errorf("Expected recorded POST request; got %v", exchanges[0])
+		}
+		if exchanges[0].StatusCode != http.StatusCreated || exchanges[0].ResponseHeader.Get("X-Request-Id") != "1" {
+			t.Errorf("Expected recorded response; got %v", exchanges[0])
+		}
+	})
+
+	t.Run("binary bodies round trip", func(t *testing.T) {
+		t.Parallel()
+		e := &Exchange{Method: http.MethodPost, URL: "/upload", RequestBody: []byte{0xff, 0x00, 0xfe}}
+		data, err := e.MarshalJSON()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		var decoded Exchange
+		if err := decoded.UnmarshalJSON(data); err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if string(decoded.RequestBody) != string(e.RequestBody) {
+			t.Errorf("Expected body %v; got %v", e.RequestBody, decoded.RequestBody)
+		}
+	})
+
+	t.Run("replay passes with ignored fields", func(t *testing.T) {
+		t.Parallel()
+		cassette, err := LoadCassette(recordCassette(t))
+		if err != nil {
+			t.Fatalf("Error loading cassette: %s", err.Error())
+		}
+		counter := counterHandler("v1")
+		tester := New(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// offset the counter so every generated value differs from the recording
+			counter.ServeHTTP(httptest.NewRecorder(), r)
+			counter.ServeHTTP(w, r)
+		}))
+		tester.Replay(cassette, ReplayOptions{
+			IgnoreHeaders: []string{"x-request-id"},
+			IgnoreFields:  []string{"id", "items.*.createdAt"},
+		})
+	})
+
+	t.Run("replay reports differences", func(t *testing.T) {
+		t.Parallel()
+		cassette, err := LoadCassette(recordCassette(t))
+		if err != nil {
+			t.Fatalf("Error loading cassette: %s", err.Error())
+		}
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, counterHandler("v2"))
+		defer func() {
+			err := recover()
+			if err == nil {
+				t.Fatalf("Expected Fatalf to be called during test.")
+			}
+			if !strings.Contains(fmt.Sprint(err), `version: expected "v1"; got "v2"`) {
+				t.Errorf("Expected version difference in failure; got %s", err)
+			}
+		}()
+		tester.Replay(cassette, ReplayOptions{IgnoreHeaders: []string{"X-Request-Id"}})
+	})
+}
@@ -0,0 +1,198 @@
+package httptesting
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// Chunk data sent to the client by a single call to Flush, or by the handler returning with unflushed data
+type Chunk struct {
+	// Data data written since the previous chunk
+	Data []byte
+
+	// Writes number of calls to Write that made up the chunk
+	Writes int
+
+	// Time time the chunk was flushed
+	Time time.Time
+
+	// Elapsed time since the request was dispatched to the handler
+	Elapsed time.Duration
+
+	// Final is true if the data was not flushed by the handler and was only sent when it returned
+	Final bool
+}
+
+// ExecuteStreaming executes the current request with a response writer that records every Write and Flush made by the handler.
+// The response can be asserted the same as Execute, and the chunks sent by each Flush with the Assert*Chunk* and Assert*Flush* functions
+func (ht *Httptester) ExecuteStreaming() {
+	ht.t.Helper()
+	expectPanic := ht.expectPanic
+	req, requestBody, release := ht.prepareRequest()
+	defer release()
+	w := newStreamWriter()
+	stopWatch := watchContext(req.Context())
+	p := ht.serve(w, req)
+	w.finish(nil)
+	duration := time.Since(w.start)
+	ht.cancellation = stopWatch()
+
+	ht.requestExecuted = true
+	ht.state.Request = nil
+	ht.state.Response = w.response(req, true)
+	ht.chunks = w.chunks
+	ht.flushes = w.flushes
+	ht.recordExchange(&Exchange{
+		Method:         req.Method,
+		URL:            req.URL.String(),
+		RequestHeader:  req.Header.Clone(),
+		RequestBody:    requestBody,
+		StatusCode:     ht.state.Response.StatusCode,
+		ResponseHeader: ht.state.Response.Header.Clone(),
+		ResponseBody:   w.flushed,
+		Start:          w.start,
+		Duration:       duration,
+	})
+	ht.handlePanic(p, expectPanic, req, requestBody)
+	ht.runResponseHooks(ht.exchange)
+}
+
+// Chunks returns the chunks sent by the previous request executed with ExecuteStreaming
+func (ht *Httptester) Chunks() []Chunk {
+	ht.t.Helper()
+	ht.assertRequestExecuted()
+	return ht.chunks
+}
+
+// AssertFlushCount asserts the handler called Flush count times
+func (ht *Httptester) AssertFlushCount(count int) {
+	ht.t.Helper()
+	defer ht.assertion("AssertFlushCount")()
+	ht.assertRequestExecuted()
+	if ht.flushes != count {
+		ht.t.Fatalf("Expected %d flushes; got %d", count, ht.flushes)
+	}
+}
+
+// AssertChunkCount asserts the response was sent in count chunks
+func (ht *Httptester) AssertChunkCount(count int) {
+	ht.t.Helper()
+	defer ht.assertion("AssertChunkCount")()
+	ht.assertRequestExecuted()
+	if len(ht.chunks) != count {
+		ht.t.Fatalf("Expected %d chunks; got %d: %q", count, len(ht.chunks), chunkData(ht.chunks))
+	}
+}
+
+// AssertChunk asserts the chunk at index i equals expected
+func (ht *Httptester) AssertChunk(i int, expected string) {
+	ht.t.Helper()
+	defer ht.assertion("AssertChunk")()
+	ht.assertRequestExecuted()
+	if i < 0 || i >= len(ht.chunks) {
+		ht.t.Fatalf("Expected chunk %d; got %d chunks", i, len(ht.chunks))
+	}
+	if string(ht.chunks[i].Data) != expected {
+		ht.t.Fatalf("Expected chunk %d to be %q; got %q", i, expected, ht.chunks[i].Data)
+	}
+}
+
+// AssertChunks asserts the response was sent in exactly the expected chunks, in order
+func (ht *Httptester) AssertChunks(expected ...string) {
+	ht.t.Helper()
+	defer ht.assertion("AssertChunks")()
+	ht.assertRequestExecuted()
+	got := chunkData(ht.chunks)
+	if len(got) != len(expected) {
+		ht.t.Fatalf("Expected chunks %q; got %q", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			ht.t.Fatalf("Expected chunks %q; got %q", expected, got)
+		}
+	}
+}
+
+// AssertAllFlushed asserts every write made by the handler was flushed before it returned
+func (ht *Httptester) AssertAllFlushed() {
+	ht.t.Helper()
+	defer ht.assertion("AssertAllFlushed")()
+	ht.assertRequestExecuted()
+	if n := len(ht.chunks); n > 0 && ht.chunks[n-1].Final {
+		ht.t.Fatalf("Expected all data to be flushed; %q was written after the last flush", ht.chunks[n-1].Data)
+	}
+}
+
+// AssertMaxFlushInterval asserts the time between consecutive chunks, and before the first chunk, never exceeded maxInterval
+func (ht *Httptester) AssertMaxFlushInterval(maxInterval time.Duration) {
+	ht.t.Helper()
+	defer ht.assertion("AssertMaxFlushInterval")()
+	ht.assertRequestExecuted()
+	var previous time.Duration
+	for i, chunk := range ht.chunks {
+		if interval := chunk.Elapsed - previous; interval > maxInterval {
+			ht.t.Fatalf("Expected at most %s between flushes; chunk %d was flushed after %s", maxInterval, i, interval)
+		}
+		previous = chunk.Elapsed
+	}
+}
+
+// AssertMinFlushInterval asserts the time between consecutive chunks was at least minInterval
+func (ht *Httptester) AssertMinFlushInterval(minInterval time.Duration) {
+	ht.t.Helper()
+	defer ht.assertion("AssertMinFlushInterval")()
+	ht.assertRequestExecuted()
+	for i := 1; i < len(ht.chunks); i++ {
+		if interval := ht.chunks[i].Elapsed - ht.chunks[i-1].Elapsed; interval < minInterval {
+			ht.t.Fatalf("Expected at least %s between flushes; chunk %d was flushed after %s", minInterval, i, interval)
+		}
+	}
+}
+
+// chunkData helper function to get the data of each chunk as strings
+func chunkData(chunks []Chunk) []string {
+	data := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		data[i] = string(chunk.Data)
+	}
+	return data
+}
+
+// NDJSONReader reads a newline delimited JSON response body one line at a time
+type NDJSONReader struct {
+	ht      *Httptester
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NDJSON returns a reader for the newline delimited JSON body of the response to the previous request
+func (ht *Httptester) NDJSON() *NDJSONReader {
+	ht.t.Helper()
+	ht.assertRequestExecuted()
+	scanner := bufio.NewScanner(bytes.NewReader(ht.responseBody()))
+	scanner.Buffer(nil, 16<<20)
+	return &NDJSONReader{ht: ht, scanner: scanner}
+}
+
+// Next decodes the next non-empty line into v. Returns false when every line was read.
+// Fails the test if a line is not valid JSON
+func (r *NDJSONReader) Next(v interface{}) bool {
+	r.ht.t.Helper()
+	for r.scanner.Scan() {
+		r.line++
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(line, v); err != nil {
+			r.ht.t.Fatalf("Error parsing NDJSON line %d: %s", r.line, err.Error())
+		}
+		return true
+	}
+	if err := r.scanner.Err(); err != nil {
+		r.ht.t.Fatalf("Error reading NDJSON body: %s", err.Error())
+	}
+	return false
+}
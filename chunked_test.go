@@ -0,0 +1,103 @@
+package httptesting
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+type exportRow struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func exportHandler(flush bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for i := 1; i <= 3; i++ {
+			fmt.Fprintf(w, `{"id": %d, "name": "row %d"}`+"\n", i, i)
+			if flush {
+				w.(http.Flusher).Flush()
+				time.Sleep(5 * time.Millisecond)
+			}
+		}
+	})
+}
+
+func TestExecuteStreaming(t *testing.T) {
+	t.Parallel()
+	t.Run("records each flushed chunk", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, exportHandler(true))
+		tester.Get("/export")
+		tester.ExecuteStreaming()
+
+		tester.AssertStatusCode(http.StatusOK)
+		tester.AssertHeader("Content-Type", "application/x-ndjson")
+		tester.AssertFlushCount(3)
+		tester.AssertChunkCount(3)
+		tester.AssertChunk(1, `{"id": 2, "name": "row 2"}`+"\n")
+		tester.AssertChunks(
+			`{"id": 1, "name": "row 1"}`+"\n",
+			`{"id": 2, "name": "row 2"}`+"\n",
+			`{"id": 3, "name": "row 3"}`+"\n",
+		)
+		tester.AssertAllFlushed()
+		tester.AssertMinFlushInterval(time.Millisecond)
+		tester.AssertMaxFlushInterval(time.Second)
+		if chunks := tester.Chunks(); chunks[0].Writes != 1 || chunks[0].Final {
+			t.Errorf("Expected first chunk to be a single flushed write; got %+v", chunks[0])
+		}
+	})
+
+	t.Run("reads ndjson into typed values", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, exportHandler(true))
+		tester.Get("/export")
+		tester.ExecuteStreaming()
+
+		var rows []exportRow
+		reader := tester.NDJSON()
+		var row exportRow
+		for reader.Next(&row) {
+			rows = append(rows, row)
+		}
+		if len(rows) != 3 || rows[2] != (exportRow{ID: 3, Name: "row 3"}) {
+			t.Errorf("Expected 3 rows; got %+v", rows)
+		}
+	})
+
+	t.Run("unflushed writes fail test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, exportHandler(false))
+		tester.Get("/export")
+		tester.ExecuteStreaming()
+		tester.AssertChunkCount(1)
+		if chunk := tester.Chunks()[0]; !chunk.Final || chunk.Writes != 3 {
+			t.Errorf("Expected a single final chunk of 3 writes; got %+v", chunk)
+		}
+
+		defer assertFatal(t)
+		tester.AssertAllFlushed()
+	})
+
+	t.Run("invalid ndjson fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "{\"id\": 1}\nnot json\n")
+		}))
+		tester.Get("/export")
+		tester.ExecuteStreaming()
+
+		reader := tester.NDJSON()
+		var row exportRow
+		reader.Next(&row)
+		defer assertFatal(t)
+		reader.Next(&row)
+	})
+}
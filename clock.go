@@ -0,0 +1,106 @@
+package httptesting
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock source of time used by the httptester when waiting between requests.
+// Pass the same Clock to the handler under test to control time-dependent behavior without real sleeps
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// Sleep pauses until d has passed
+	Sleep(d time.Duration)
+}
+
+// realClock Clock backed by the time package
+type realClock struct{}
+
+// Now returns time.Now()
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep calls time.Sleep(d)
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// SetClock sets the clock used by the httptester. Defaults to the system clock.
+// The clock is passed to the handler in the context of every request, see ClockFromContext, and is used to expire session cookies
+func (ht *Httptester) SetClock(c Clock) {
+	ht.clock = c
+}
+
+// getClock helper function for getting the clock of the httptester
+func (ht *Httptester) getClock() Clock {
+	if ht.clock == nil {
+		return realClock{}
+	}
+	return ht.clock
+}
+
+// FakeClock Clock that only moves when it is advanced. Sleep advances the clock instead of blocking.
+// A FakeClock is safe for concurrent use, so it can be shared with the handler under test
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a new FakeClock set to now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current time of the clock
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep advances the clock by d
+func (c *FakeClock) Sleep(d time.Duration) {
+	c.Advance(d)
+}
+
+// Advance moves the clock forward by d
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set sets the current time of the clock
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Advance moves the FakeClock set with SetClock forward by d between requests
+func (ht *Httptester) Advance(d time.Duration) {
+	ht.t.Helper()
+	clock, ok := ht.clock.(*FakeClock)
+	if !ok {
+		ht.t.Fatalf("Advance requires a FakeClock to be set with SetClock")
+	}
+	clock.Advance(d)
+}
+
+// clockKey context key of the clock of the httptester
+type clockKey struct{}
+
+// ClockFromContext returns the clock set with SetClock from the context of a request executed by an httptester,
+// or the system clock if none was set. Handlers call it to read the time the test controls:
+//
+//	expired := httptesting.ClockFromContext(r.Context()).Now().After(token.Expiry)
+func ClockFromContext(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return clock
+	}
+	return realClock{}
+}
@@ -0,0 +1,89 @@
+package httptesting
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// tokenHandler issues a token valid for a minute on /login and accepts it on /me until it expires
+func tokenHandler() http.Handler {
+	var expiry time.Time
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		expiry = ClockFromContext(r.Context()).Now().Add(time.Minute)
+		_, err := w.Write([]byte("Ok"))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		if !ClockFromContext(r.Context()).Now().Before(expiry) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, err := w.Write([]byte("Ok"))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	return mux
+}
+
+func TestFakeClock(t *testing.T) {
+	t.Parallel()
+	t.Run("handler reads the clock from the request context", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, tokenHandler())
+		tester.SetClock(NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+		tester.Get("/login")
+		tester.Execute()
+
+		tester.Advance(59 * time.Second)
+		tester.Get("/me")
+		tester.Execute()
+		tester.AssertStatusCode(http.StatusOK)
+
+		tester.Advance(time.Second)
+		tester.Get("/me")
+		tester.Execute()
+		tester.AssertStatusCode(http.StatusUnauthorized)
+	})
+
+	t.Run("sleep advances the clock", func(t *testing.T) {
+		t.Parallel()
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock := NewFakeClock(start)
+		clock.Sleep(time.Hour)
+		if got := clock.Now(); !got.Equal(start.Add(time.Hour)) {
+			t.Fatalf("Expected %s; got %s", start.Add(time.Hour), got)
+		}
+		clock.Set(start)
+		if got := clock.Now(); !got.Equal(start) {
+			t.Fatalf("Expected %s; got %s", start, got)
+		}
+	})
+
+	t.Run("system clock without SetClock", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := ClockFromContext(r.Context()).(realClock); !ok {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		tester.Get("/")
+		tester.Execute()
+		tester.AssertStatusCode(http.StatusOK)
+	})
+
+	t.Run("advance without a fake clock fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, tokenHandler())
+
+		defer assertFatal(t)
+		tester.Advance(time.Second)
+	})
+}
@@ -0,0 +1,16 @@
+// Command httptesting runs httptesting scenario files against a live server.
+//
+// Usage:
+//
+//	httptesting -base-url http://localhost:8080 [flags] scenario.json...
+//
+// The exit code is 0 when every scenario passes, 1 when a scenario fails and 2 on usage or loading errors.
+package main
+
+import (
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
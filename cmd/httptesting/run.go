@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hunterwilkins2/httptesting"
+)
+
+const (
+	exitPass  = 0
+	exitFail  = 1
+	exitUsage = 2
+)
+
+// config command line configuration
+type config struct {
+	baseURL  string
+	parallel int
+	tags     []string
+	run      *regexp.Regexp
+	envFiles []string
+	format   string
+	timeout  time.Duration
+	files    []string
+}
+
+// result outcome of a single scenario
+type result struct {
+	Name     string        `json:"name"`
+	File     string        `json:"file"`
+	Passed   bool          `json:"passed"`
+	Duration time.Duration `json:"duration_ns"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// report machine readable output of a run
+type report struct {
+	Passed    int      `json:"passed"`
+	Failed    int      `json:"failed"`
+	Scenarios []result `json:"scenarios"`
+}
+
+// scenarioFile a scenario and the file it was loaded from
+type scenarioFile struct {
+	file     string
+	scenario httptesting.Scenario
+}
+
+// run parses args, runs the selected scenarios and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	cfg, err := parseFlags(args, stderr)
+	if err != nil {
+		return exitUsage
+	}
+
+	vars, err := loadVars(cfg.envFiles)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %s\n", err.Error())
+		return exitUsage
+	}
+
+	var scenarios []scenarioFile
+	for _, file := range cfg.files {
+		loaded, err := httptesting.LoadScenarioFile(file)
+		if err != nil {
+			fmt.Fprintf(stderr, "error: loading %s: %s\n", file, err.Error())
+			return exitUsage
+		}
+		for _, s := range loaded {
+			if len(cfg.tags) > 0 && !s.HasAnyTag(cfg.tags...) {
+				continue
+			}
+			if cfg.run != nil && !cfg.run.MatchString(s.Name) {
+				continue
+			}
+			scenarios = append(scenarios, scenarioFile{file: file, scenario: s})
+		}
+	}
+
+	client := &http.Client{
+		Timeout: cfg.timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	results := runScenarios(cfg, client, vars, scenarios)
+
+	rep := report{Scenarios: results}
+	for _, r := range results {
+		if r.Passed {
+			rep.Passed++
+		} else {
+			rep.Failed++
+		}
+	}
+	if cfg.format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(rep); err != nil {
+			fmt.Fprintf(stderr, "error: %s\n", err.Error())
+		}
+	} else {
+		writeText(stdout, rep)
+	}
+
+	if rep.Failed > 0 {
+		return exitFail
+	}
+	return exitPass
+}
+
+// parseFlags parses the command line arguments
+func parseFlags(args []string, stderr io.Writer) (*config, error) {
+	cfg := &config{}
+	var tags, run string
+
+	fs := flag.NewFlagSet("httptesting", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: httptesting -base-url URL [flags] scenario.json...\n\n")
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&cfg.baseURL, "base-url", "", "base url of the server under test (required)")
+	fs.IntVar(&cfg.parallel, "parallel", 1, "number of scenarios to run concurrently")
+	fs.StringVar(&tags, "tags", "", "comma separated list of tags; only scenarios with at least one tag are run")
+	fs.StringVar(&run, "run", "", "regular expression; only scenarios with a matching name are run")
+	fs.Func("env", "file of KEY=VALUE variables; may be repeated", func(s string) error {
+		cfg.envFiles = append(cfg.envFiles, s)
+		return nil
+	})
+	fs.StringVar(&cfg.format, "format", "text", "output format: text or json")
+	fs.DurationVar(&cfg.timeout, "timeout", 30*time.Second, "timeout for each request")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	cfg.files = fs.Args()
+
+	var err error
+	switch {
+	case cfg.baseURL == "":
+		err = fmt.Errorf("-base-url is required")
+	case len(cfg.files) == 0:
+		err = fmt.Errorf("at least one scenario file is required")
+	case cfg.parallel < 1:
+		err = fmt.Errorf("-parallel must be at least 1")
+	case cfg.format != "text" && cfg.format != "json":
+		err = fmt.Errorf("-format must be text or json")
+	}
+	if err == nil && run != "" {
+		cfg.run, err = regexp.Compile(run)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %s\n", err.Error())
+		fs.Usage()
+		return nil, err
+	}
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			cfg.tags = append(cfg.tags, tag)
+		}
+	}
+	return cfg, nil
+}
+
+// loadVars returns the process environment overridden by the variables in each env file
+func loadVars(files []string) (map[string]string, error) {
+	vars := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			vars[key] = value
+		}
+	}
+	for _, file := range files {
+		if err := readEnvFile(file, vars); err != nil {
+			return nil, err
+		}
+	}
+	return vars, nil
+}
+
+// readEnvFile reads KEY=VALUE lines into vars. Blank lines and lines starting with # are ignored
+func readEnvFile(file string, vars map[string]string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected KEY=VALUE", file, n)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		vars[strings.TrimSpace(key)] = value
+	}
+	return scanner.Err()
+}
+
+// runScenarios runs the scenarios with at most cfg.parallel running at once.
+// Results are returned in the same order as scenarios
+func runScenarios(cfg *config, client *http.Client, vars map[string]string, scenarios []scenarioFile) []result {
+	results := make([]result, len(scenarios))
+	sem := make(chan struct{}, cfg.parallel)
+	var wg sync.WaitGroup
+	for i, s := range scenarios {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, s scenarioFile) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = runScenario(cfg.baseURL, client, vars, s)
+		}(i, s)
+	}
+	wg.Wait()
+	return results
+}
+
+// runScenario runs a single scenario in its own goroutine so a failed assertion can stop it with runtime.Goexit
+func runScenario(baseURL string, client *http.Client, vars map[string]string, s scenarioFile) result {
+	t := &scenarioT{}
+	start := time.Now()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if err := recover(); err != nil {
+				t.fail(fmt.Sprintf("panic: %v", err))
+			}
+		}()
+		tester := httptesting.NewWithBaseURL(httptesting.AdaptT(t), baseURL, client)
+		tester.RunScenario(s.scenario, vars)
+	}()
+	<-done
+
+	return result{
+		Name:     s.scenario.Name,
+		File:     s.file,
+		Passed:   t.message == "",
+		Duration: time.Since(start),
+		Error:    t.message,
+	}
+}
+
+// scenarioT TestingT used outside of go test. Fatalf records the failure and stops the scenario goroutine
+type scenarioT struct {
+	message string
+}
+
+// Fatalf records the failure and exits the current goroutine
+func (t *scenarioT) Fatalf(format string, args ...any) {
+	t.fail(fmt.Sprintf(format, args...))
+	runtime.Goexit()
+}
+
+// fail records the first failure of the scenario
+func (t *scenarioT) fail(message string) {
+	if t.message == "" {
+		t.message = message
+	}
+}
+
+// writeText writes the human readable output of a run
+func writeText(w io.Writer, rep report) {
+	for _, r := range rep.Scenarios {
+		if r.Passed {
+			fmt.Fprintf(w, "PASS  %s (%s)\n", r.Name, r.Duration.Round(time.Millisecond))
+			continue
+		}
+		fmt.Fprintf(w, "FAIL  %s (%s)\n", r.Name, r.Duration.Round(time.Millisecond))
+		fmt.Fprintf(w, "      %s: %s\n", r.File, r.Error)
+	}
+	fmt.Fprintf(w, "\n%d passed, %d failed\n", rep.Passed, rep.Failed)
+}
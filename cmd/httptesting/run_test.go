@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Error writing %s: %s", name, err.Error())
+	}
+	return path
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, err := w.Write([]byte("pong"))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+const scenarios = `[
+	{
+		"name": "ping",
+		"tags": ["smoke"],
+		"steps": [{"path": "/ping", "headers": {"X-Api-Key": "${API_KEY}"}, "expect": {"status": 200, "body": "pong"}}]
+	},
+	{
+		"name": "ping unauthorized",
+		"tags": ["auth"],
+		"steps": [{"path": "/ping", "expect": {"status": 200}}]
+	}
+]`
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	t.Run("passing scenarios exit with 0", func(t *testing.T) {
+		t.Parallel()
+		server := newServer(t)
+		file := writeFile(t, "scenarios.json", scenarios)
+		env := writeFile(t, ".env", "# comment\nAPI_KEY=\"secret\"\n")
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-base-url", server.URL, "-env", env, "-tags", "smoke", file}, &stdout, &stderr)
+		if code != exitPass {
+			t.Fatalf("Expected exit code %d; got %d: %s%s", exitPass, code, stdout.String(), stderr.String())
+		}
+		if !strings.Contains(stdout.String(), "PASS  ping") {
+			t.Errorf("Expected output to contain passing scenario; got %s", stdout.String())
+		}
+	})
+
+	t.Run("failing scenarios exit with 1", func(t *testing.T) {
+		t.Parallel()
+		server := newServer(t)
+		file := writeFile(t, "scenarios.json", scenarios)
+		env := writeFile(t, ".env", "API_KEY=secret\n")
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-base-url", server.URL, "-env", env, "-parallel", "2", "-format", "json", file}, &stdout, &stderr)
+		if code != exitFail {
+			t.Fatalf("Expected exit code %d; got %d", exitFail, code)
+		}
+		var rep report
+		if err := json.Unmarshal(stdout.Bytes(), &rep); err != nil {
+			t.Fatalf("Error decoding json output: %s", err.Error())
+		}
+		if rep.Passed != 1 || rep.Failed != 1 {
+			t.Fatalf("Expected 1 passed and 1 failed; got %+v", rep)
+		}
+		if rep.Scenarios[1].Name != "ping unauthorized" || rep.Scenarios[1].Error == "" {
+			t.Errorf("Expected failure message for %q; got %+v", "ping unauthorized", rep.Scenarios[1])
+		}
+	})
+
+	t.Run("name filter selects scenarios", func(t *testing.T) {
+		t.Parallel()
+		server := newServer(t)
+		file := writeFile(t, "scenarios.json", scenarios)
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-base-url", server.URL, "-run", "unauthorized$", file}, &stdout, &stderr)
+		if code != exitFail {
+			t.Fatalf("Expected exit code %d; got %d", exitFail, code)
+		}
+		if strings.Contains(stdout.String(), "PASS") || !strings.Contains(stdout.String(), "0 passed, 1 failed") {
+			t.Errorf("Expected only the unauthorized scenario to run; got %s", stdout.String())
+		}
+	})
+
+	t.Run("usage errors exit with 2", func(t *testing.T) {
+		t.Parallel()
+		var stdout, stderr bytes.Buffer
+		if code := run([]string{"scenarios.json"}, &stdout, &stderr); code != exitUsage {
+			t.Errorf("Expected exit code %d without base url; got %d", exitUsage, code)
+		}
+		if code := run([]string{"-base-url", "http://localhost", "missing.json"}, &stdout, &stderr); code != exitUsage {
+			t.Errorf("Expected exit code %d for missing file; got %d", exitUsage, code)
+		}
+	})
+}
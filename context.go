@@ -0,0 +1,176 @@
+package httptesting
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// requestContext options applied to the context of the current request when it is executed
+type requestContext struct {
+	ctx         context.Context
+	values      [][2]any
+	deadline    time.Time
+	timeout     time.Duration
+	cancelAfter time.Duration
+}
+
+// cancellation records when the context of the previous request was done and when the handler returned
+type cancellation struct {
+	err        error
+	canceledAt time.Time
+	returnedAt time.Time
+}
+
+// getRequestContext helper function for getting the context options of the current request being built
+func (ht *Httptester) getRequestContext() *requestContext {
+	ht.getRequest()
+	if ht.requestContext == nil {
+		ht.requestContext = &requestContext{}
+	}
+	return ht.requestContext
+}
+
+// SetContext sets the parent context of the current request
+func (ht *Httptester) SetContext(ctx context.Context) {
+	ht.getRequestContext().ctx = ctx
+}
+
+// SetDeadline cancels the context of the current request at deadline
+func (ht *Httptester) SetDeadline(deadline time.Time) {
+	ht.getRequestContext().deadline = deadline
+}
+
+// SetTimeout sets a deadline on the context of the current request of timeout after it is dispatched to the handler
+func (ht *Httptester) SetTimeout(timeout time.Duration) {
+	ht.getRequestContext().timeout = timeout
+}
+
+// CancelAfter cancels the context of the current request d after it is dispatched to the handler,
+// the same as a client disconnecting
+func (ht *Httptester) CancelAfter(d time.Duration) {
+	ht.getRequestContext().cancelAfter = d
+}
+
+// AddContextValue adds a value to the context of the current request, e.g. an authenticated principal or a trace id
+func (ht *Httptester) AddContextValue(key, value any) {
+	rc := ht.getRequestContext()
+	rc.values = append(rc.values, [2]any{key, value})
+}
+
+// applyContext helper function to apply the context options of the current request.
+// The returned func releases the context and must be called once the handler returns
+func (ht *Httptester) applyContext(req *http.Request) (*http.Request, context.CancelFunc) {
+	rc := ht.requestContext
+	ht.requestContext = nil
+	if rc == nil {
+		return req, func() {}
+	}
+
+	ctx := req.Context()
+	if rc.ctx != nil {
+		ctx = rc.ctx
+	}
+	for _, kv := range rc.values {
+		ctx = context.WithValue(ctx, kv[0], kv[1])
+	}
+	var cancels []context.CancelFunc
+	if !rc.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, rc.deadline)
+		cancels = append(cancels, cancel)
+	}
+	if rc.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, rc.timeout)
+		cancels = append(cancels, cancel)
+	}
+	if rc.cancelAfter > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		timer := time.AfterFunc(rc.cancelAfter, cancel)
+		cancels = append(cancels, func() {
+			timer.Stop()
+			cancel()
+		})
+	}
+	return req.WithContext(ctx), func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}
+}
+
+// watchContext helper function to record when ctx is done while the handler is running.
+// The returned func must be called when the handler returns
+func watchContext(ctx context.Context) func() *cancellation {
+	if ctx.Done() == nil {
+		return func() *cancellation { return nil }
+	}
+	stop := make(chan struct{})
+	canceled := make(chan time.Time, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			canceled <- time.Now()
+		case <-stop:
+			close(canceled)
+		}
+	}()
+	return func() *cancellation {
+		returnedAt := time.Now()
+		close(stop)
+		canceledAt, ok := <-canceled
+		if !ok {
+			if ctx.Err() == nil {
+				return nil
+			}
+			canceledAt = returnedAt
+		}
+		return &cancellation{err: ctx.Err(), canceledAt: canceledAt, returnedAt: returnedAt}
+	}
+}
+
+// AssertContextCanceled asserts the context of the previous request was canceled or timed out before the handler returned
+func (ht *Httptester) AssertContextCanceled() {
+	ht.t.Helper()
+	defer ht.assertion("AssertContextCanceled")()
+	ht.assertRequestExecuted()
+	if ht.cancellation == nil {
+		ht.t.Fatalf("Expected request context to be canceled before the handler returned")
+	}
+}
+
+// AssertContextNotCanceled asserts the handler returned before the context of the previous request was canceled
+func (ht *Httptester) AssertContextNotCanceled() {
+	ht.t.Helper()
+	defer ht.assertion("AssertContextNotCanceled")()
+	ht.assertRequestExecuted()
+	if ht.cancellation != nil {
+		ht.t.Fatalf("Expected handler to return before the request context was done; got %s", ht.cancellation.err)
+	}
+}
+
+// AssertDeadlineExceeded asserts the context of the previous request reached its deadline before the handler returned
+func (ht *Httptester) AssertDeadlineExceeded() {
+	ht.t.Helper()
+	defer ht.assertion("AssertDeadlineExceeded")()
+	ht.assertRequestExecuted()
+	if ht.cancellation == nil || !errors.Is(ht.cancellation.err, context.DeadlineExceeded) {
+		ht.t.Fatalf("Expected request context deadline to be exceeded before the handler returned")
+	}
+}
+
+// AssertReturnedWithin asserts the handler returned within d of the context of the previous request being canceled
+func (ht *Httptester) AssertReturnedWithin(d time.Duration) {
+	ht.t.Helper()
+	defer ht.assertion("AssertReturnedWithin")()
+	ht.assertRequestExecuted()
+	if ht.cancellation == nil {
+		ht.t.Fatalf("Expected request context to be canceled before the handler returned")
+	}
+	if took := ht.cancellation.returnedAt.Sub(ht.cancellation.canceledAt); took > d {
+		ht.t.Fatalf("Expected handler to return within %s of the request being canceled; took %s", d, took)
+	}
+}
@@ -0,0 +1,118 @@
+package httptesting
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+type principalKey struct{}
+
+func slowHandler(respectCancel bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte("partial"))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if !respectCancel {
+			time.Sleep(50 * time.Millisecond)
+			return
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+			_, err = w.Write([]byte(" complete"))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}
+	})
+}
+
+func TestRequestContext(t *testing.T) {
+	t.Parallel()
+	t.Run("context values are passed to the handler", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, _ := r.Context().Value(principalKey{}).(string)
+			_, err := w.Write([]byte(principal))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		tester.Get("/me")
+		tester.AddContextValue(principalKey{}, "john")
+		tester.Execute()
+		tester.AssertBody([]byte("john"))
+		tester.AssertContextNotCanceled()
+
+		tester.Get("/me")
+		tester.Execute()
+		tester.AssertBody([]byte(""))
+	})
+
+	t.Run("cancel after duration", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, slowHandler(true))
+		tester.Get("/slow")
+		tester.CancelAfter(10 * time.Millisecond)
+		tester.Execute()
+		tester.AssertContextCanceled()
+		tester.AssertReturnedWithin(100 * time.Millisecond)
+		tester.AssertBody([]byte("partial"))
+	})
+
+	t.Run("timeout and deadline", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, slowHandler(true))
+		tester.Get("/slow")
+		tester.SetTimeout(10 * time.Millisecond)
+		tester.Execute()
+		tester.AssertDeadlineExceeded()
+
+		tester.Get("/slow")
+		tester.SetDeadline(time.Now().Add(10 * time.Millisecond))
+		tester.Execute()
+		tester.AssertDeadlineExceeded()
+	})
+
+	t.Run("parent context", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		tester := New(t, slowHandler(true))
+		tester.Get("/slow")
+		tester.SetContext(ctx)
+		tester.Execute()
+		tester.AssertContextCanceled()
+		tester.AssertReturnedWithin(100 * time.Millisecond)
+	})
+
+	t.Run("handler ignoring cancellation fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, slowHandler(false))
+		tester.Get("/slow")
+		tester.CancelAfter(time.Millisecond)
+		tester.Execute()
+		tester.AssertContextCanceled()
+
+		defer assertFatal(t)
+		tester.AssertReturnedWithin(10 * time.Millisecond)
+	})
+
+	t.Run("uncanceled request fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, slowHandler(false))
+		tester.Get("/slow")
+		tester.Execute()
+
+		defer assertFatal(t)
+		tester.AssertContextCanceled()
+	})
+}
@@ -0,0 +1,95 @@
+package httptesting
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// sessionCookie cookie stored in the session jar with its expiry evaluated against the clock of the httptester
+type sessionCookie struct {
+	cookie *http.Cookie
+	// expires zero for session cookies that never expire
+	expires time.Time
+}
+
+// storeCookies helper function to store the cookies set by a response in the session jar.
+// Cookies with a negative Max-Age or an expiry in the past are removed
+func (ht *Httptester) storeCookies(header http.Header) {
+	now := ht.getClock().Now()
+	for _, cookie := range (&http.Response{Header: header}).Cookies() {
+		stored := sessionCookie{cookie: cookie}
+		switch {
+		case cookie.MaxAge < 0:
+			stored.expires = now
+		case cookie.MaxAge > 0:
+			stored.expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+		case !cookie.Expires.IsZero():
+			stored.expires = cookie.Expires
+		}
+
+		replaced := false
+		for i, c := range ht.cookies {
+			if c.cookie.Name == cookie.Name && c.cookie.Path == cookie.Path && c.cookie.Domain == cookie.Domain {
+				ht.cookies[i] = stored
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			ht.cookies = append(ht.cookies, stored)
+		}
+	}
+}
+
+// addSessionCookies helper function to add the unexpired cookies of the session jar that match the path of req.
+// Cookies already set on the request take precedence
+func (ht *Httptester) addSessionCookies(req *http.Request) {
+	for _, c := range ht.liveCookies() {
+		if !cookiePathMatches(c.Path, req.URL.Path) {
+			continue
+		}
+		if _, err := req.Cookie(c.Name); err == nil {
+			continue
+		}
+		req.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+	}
+}
+
+// liveCookies helper function to remove expired cookies from the session jar and return the rest
+func (ht *Httptester) liveCookies() []*http.Cookie {
+	now := ht.getClock().Now()
+	live := ht.cookies[:0]
+	cookies := make([]*http.Cookie, 0, len(ht.cookies))
+	for _, c := range ht.cookies {
+		if !c.expires.IsZero() && !now.Before(c.expires) {
+			continue
+		}
+		live = append(live, c)
+		cookies = append(cookies, c.cookie)
+	}
+	ht.cookies = live
+	return cookies
+}
+
+// cookiePathMatches returns true if a cookie with cookiePath is sent with a request to requestPath
+func cookiePathMatches(cookiePath, requestPath string) bool {
+	if cookiePath == "" || cookiePath == "/" || cookiePath == requestPath {
+		return true
+	}
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
+
+// Cookies returns the unexpired cookies in the session jar. Cookies set by every response are stored in the jar
+// and sent with all subsequent requests until they expire according to the clock of the httptester
+func (ht *Httptester) Cookies() []*http.Cookie {
+	return ht.liveCookies()
+}
+
+// ClearCookies removes every cookie from the session jar
+func (ht *Httptester) ClearCookies() {
+	ht.cookies = nil
+}
@@ -0,0 +1,112 @@
+package httptesting
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// sessionHandler sets cookies with different lifetimes on /login, clears session on /logout and echoes the cookies it receives
+func sessionHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", MaxAge: 60})
+		http.SetCookie(w, &http.Cookie{Name: "remember", Value: "me"})
+		http.SetCookie(w, &http.Cookie{Name: "admin", Value: "yes", Path: "/admin"})
+	})
+	mux.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", MaxAge: -1})
+	})
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		for _, cookie := range r.Cookies() {
+			_, err := w.Write([]byte(cookie.Name + ";"))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}
+	}
+	mux.HandleFunc("/", echo)
+	mux.HandleFunc("/admin/", echo)
+	return mux
+}
+
+func TestSessionCookies(t *testing.T) {
+	t.Parallel()
+	t.Run("cookies are chained until they expire", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, sessionHandler())
+		tester.SetClock(NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+		tester.Get("/login")
+		tester.Execute()
+
+		tester.Get("/")
+		tester.Execute()
+		tester.AssertBody([]byte("session;remember;"))
+		tester.Get("/")
+		tester.Execute()
+		tester.AssertBody([]byte("session;remember;"))
+
+		tester.Advance(time.Minute)
+		tester.Get("/")
+		tester.Execute()
+		tester.AssertBody([]byte("remember;"))
+		if cookies := tester.Cookies(); len(cookies) != 2 {
+			t.Fatalf("Expected 2 cookies in the jar; got %d", len(cookies))
+		}
+	})
+
+	t.Run("cookies are sent to matching paths", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, sessionHandler())
+		tester.Get("/login")
+		tester.Execute()
+		tester.Get("/admin/users")
+		tester.Execute()
+		tester.AssertBody([]byte("session;remember;admin;"))
+		tester.Get("/administrator")
+		tester.Execute()
+		tester.AssertBody([]byte("session;remember;"))
+	})
+
+	t.Run("negative max age removes the cookie", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, sessionHandler())
+		tester.Get("/login")
+		tester.Execute()
+		tester.Get("/logout")
+		tester.Execute()
+		tester.Get("/")
+		tester.Execute()
+		tester.AssertBody([]byte("remember;"))
+	})
+
+	t.Run("request cookies take precedence", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/login" {
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+				return
+			}
+			cookie, err := r.Cookie("session")
+			if err != nil || len(r.Cookies()) != 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, err = w.Write([]byte(cookie.Value))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		tester.Get("/login")
+		tester.Execute()
+		tester.Get("/")
+		tester.AddCookie(&http.Cookie{Name: "session", Value: "override"})
+		tester.Execute()
+		tester.AssertBody([]byte("override"))
+
+		tester.ClearCookies()
+		tester.Get("/")
+		tester.Execute()
+		tester.AssertStatusCode(http.StatusBadRequest)
+	})
+}
@@ -0,0 +1,250 @@
+package httptesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	urlpkg "net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DocRecorder collects the requests marked with DocExample and their responses as named examples and writes them
+// as Markdown or OpenAPI examples. An example is only kept once its test passes, and credentials in the Authorization,
+// Proxy-Authorization, Cookie, Set-Cookie and X-Api-Key headers are replaced with [REDACTED]. Examples are sorted by
+// path, method and name, so docs generated from parallel tests are the same on every run
+type DocRecorder struct {
+	mu       sync.Mutex
+	examples []docExample
+}
+
+// docExample a named exchange and the documented path it belongs to
+type docExample struct {
+	name     string
+	path     string
+	exchange *Exchange
+}
+
+// docRedactedHeaders headers whose values are redacted in documentation examples
+var docRedactedHeaders = redactedSet(defaultRedactedHeaders)
+
+// NewDocRecorder returns a new DocRecorder
+func NewDocRecorder() *DocRecorder {
+	return &DocRecorder{}
+}
+
+// SetDocRecorder captures requests marked with DocExample in d. Examples are added to d when the test finishes,
+// so the TestingT of the httptester must support Cleanup
+func (ht *Httptester) SetDocRecorder(d *DocRecorder) {
+	ht.docs = d
+}
+
+// DocExample marks the current request to be captured as a documentation example named name when it is executed
+func (ht *Httptester) DocExample(name string) {
+	ht.DocExampleForPath(name, "")
+}
+
+// DocExampleForPath marks the current request to be captured as a documentation example named name
+// and documents it under the path template path, e.g. "/todo/{id}"
+func (ht *Httptester) DocExampleForPath(name string, path string) {
+	ht.getRequest()
+	ht.docExample = &docExample{name: name, path: path}
+}
+
+// recordDocExample captures the previous exchange, with its credentials redacted, if it was marked with DocExample.
+// The example is added to the DocRecorder when the test finishes, unless the test failed
+func (ht *Httptester) recordDocExample() {
+	example := ht.docExample
+	ht.docExample = nil
+	if example == nil || ht.docs == nil {
+		return
+	}
+	e := *ht.exchange
+	e.RequestHeader = redactHeader(e.RequestHeader, docRedactedHeaders)
+	e.ResponseHeader = redactHeader(e.ResponseHeader, docRedactedHeaders)
+	example.exchange = &e
+	if example.path == "" {
+		if u, err := urlpkg.Parse(e.URL); err == nil {
+			example.path = u.Path
+		}
+	}
+	docs, t := ht.docs, ht.t
+	t.Cleanup(func() {
+		if testFailed(t) {
+			return
+		}
+		docs.mu.Lock()
+		docs.examples = append(docs.examples, *example)
+		docs.mu.Unlock()
+	})
+}
+
+// sorted returns the examples sorted by path, method and name so output is stable between runs
+func (d *DocRecorder) sorted() []docExample {
+	d.mu.Lock()
+	examples := append([]docExample(nil), d.examples...)
+	d.mu.Unlock()
+	sort.SliceStable(examples, func(i, j int) bool {
+		a, b := examples[i], examples[j]
+		if a.path != b.path {
+			return a.path < b.path
+		}
+		if a.exchange.Method != b.exchange.Method {
+			return a.exchange.Method < b.exchange.Method
+		}
+		return a.name < b.name
+	})
+	return examples
+}
+
+// WriteMarkdown writes every captured example as Markdown, grouped by method and path
+func (d *DocRecorder) WriteMarkdown(w io.Writer) error {
+	var buf bytes.Buffer
+	var heading string
+	for _, example := range d.sorted() {
+		e := example.exchange
+		if h := e.Method + " " + example.path; h != heading {
+			heading = h
+			fmt.Fprintf(&buf, "## %s\n\n", heading)
+		}
+		fmt.Fprintf(&buf, "### %s\n\n", example.name)
+
+		buf.WriteString("Request\n\n```http\n")
+		fmt.Fprintf(&buf, "%s %s HTTP/1.1\n", e.Method, e.URL)
+		writeDocHeaders(&buf, e.RequestHeader)
+		writeDocBody(&buf, e.RequestBody)
+		buf.WriteString("```\n\n")
+
+		buf.WriteString("Response\n\n```http\n")
+		fmt.Fprintf(&buf, "HTTP/1.1 %d %s\n", e.StatusCode, http.StatusText(e.StatusCode))
+		writeDocHeaders(&buf, e.ResponseHeader)
+		writeDocBody(&buf, e.ResponseBody)
+		buf.WriteString("```\n\n")
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeDocHeaders writes headers sorted by key
+func writeDocHeaders(buf *bytes.Buffer, header http.Header) {
+	for _, key := range sortedKeys(header) {
+		for _, value := range header[key] {
+			fmt.Fprintf(buf, "%s: %s\n", key, value)
+		}
+	}
+}
+
+// writeDocBody writes a body, indenting it if it is JSON
+func writeDocBody(buf *bytes.Buffer, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	buf.WriteString("\n")
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err == nil {
+		body = indented.Bytes()
+	}
+	buf.Write(body)
+	if !bytes.HasSuffix(body, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+}
+
+// exampleKeyPattern matches characters replaced in OpenAPI example keys
+var exampleKeyPattern = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// openAPIExample OpenAPI Example Object
+type openAPIExample struct {
+	Summary string `json:"summary"`
+	Value   any    `json:"value"`
+}
+
+// openAPIMediaType OpenAPI Media Type Object containing only examples
+type openAPIMediaType struct {
+	Examples map[string]openAPIExample `json:"examples"`
+}
+
+// openAPIResponse OpenAPI Response Object
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+// openAPIOperation OpenAPI Operation Object containing only examples
+type openAPIOperation struct {
+	RequestBody *struct {
+		Content map[string]openAPIMediaType `json:"content"`
+	} `json:"requestBody,omitempty"`
+	Responses map[string]openAPIResponse `json:"responses"`
+}
+
+// WriteOpenAPIExamples writes a partial OpenAPI document containing the request and response examples
+// of every captured example under paths, ready to be merged into an existing specification
+func (d *DocRecorder) WriteOpenAPIExamples(w io.Writer) error {
+	paths := make(map[string]map[string]*openAPIOperation)
+	for _, example := range d.sorted() {
+		e := example.exchange
+		if paths[example.path] == nil {
+			paths[example.path] = make(map[string]*openAPIOperation)
+		}
+		method := strings.ToLower(e.Method)
+		operation := paths[example.path][method]
+		if operation == nil {
+			operation = &openAPIOperation{Responses: make(map[string]openAPIResponse)}
+			paths[example.path][method] = operation
+		}
+		key := strings.Trim(exampleKeyPattern.ReplaceAllString(example.name, "-"), "-")
+
+		if len(e.RequestBody) > 0 {
+			if operation.RequestBody == nil {
+				operation.RequestBody = &struct {
+					Content map[string]openAPIMediaType `json:"content"`
+				}{Content: make(map[string]openAPIMediaType)}
+			}
+			addOpenAPIExample(operation.RequestBody.Content, e.RequestHeader, e.RequestBody, key, example.name)
+		}
+
+		status := fmt.Sprint(e.StatusCode)
+		response, ok := operation.Responses[status]
+		if !ok {
+			response = openAPIResponse{Description: http.StatusText(e.StatusCode), Content: make(map[string]openAPIMediaType)}
+		}
+		if len(e.ResponseBody) > 0 {
+			addOpenAPIExample(response.Content, e.ResponseHeader, e.ResponseBody, key, example.name)
+		}
+		operation.Responses[status] = response
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]any{"paths": paths})
+}
+
+// addOpenAPIExample adds a body as an example to the media type of its Content-Type header
+func addOpenAPIExample(content map[string]openAPIMediaType, header http.Header, body []byte, key, name string) {
+	var value any = string(body)
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err == nil {
+		value = decoded
+	}
+
+	mediaType := "application/octet-stream"
+	if _, ok := value.(string); !ok {
+		mediaType = "application/json"
+	}
+	if parsed, _, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil {
+		mediaType = parsed
+	}
+
+	media, ok := content[mediaType]
+	if !ok {
+		media = openAPIMediaType{Examples: make(map[string]openAPIExample)}
+		content[mediaType] = media
+	}
+	media.Examples[key] = openAPIExample{Summary: name, Value: value}
+}
@@ -0,0 +1,147 @@
+package httptesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+func TestDocRecorder(t *testing.T) {
+	t.Parallel()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		_, err := w.Write([]byte(`{"id":1,"name":"Get Groceries"}`))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+
+	record := func(t *testing.T) *DocRecorder {
+		docs := NewDocRecorder()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, handler)
+		tester.SetDocRecorder(docs)
+
+		tester.Post("/todo", strings.NewReader(`{"name":"Get Groceries"}`))
+		tester.AddHeader("Content-Type", "application/json")
+		tester.DocExample("Create a todo")
+		tester.Execute()
+		tester.AssertStatusCode(http.StatusCreated)
+
+		tester.Get("/todo/1")
+		tester.DocExampleForPath("Get a todo", "/todo/{id}")
+		tester.Execute()
+
+		tester.Get("/todo/2")
+		tester.Execute()
+		mockT.RunCleanups()
+		return docs
+	}
+
+	t.Run("writes markdown", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		if err := record(t).WriteMarkdown(&buf); err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		markdown := buf.String()
+		for _, want := range []string{
+			"## POST /todo\n\n### Create a todo",
+			"POST /todo HTTP/1.1\nContent-Type: application/json\n\n{\n  \"name\": \"Get Groceries\"\n}",
+			"HTTP/1.1 201 Created",
+			"## GET /todo/{id}\n\n### Get a todo",
+		} {
+			if !strings.Contains(markdown, want) {
+				t.Errorf("Expected markdown to contain %q; got %s", want, markdown)
+			}
+		}
+		if strings.Contains(markdown, "/todo/2") {
+			t.Errorf("Expected unmarked request to not be documented")
+		}
+	})
+
+	t.Run("writes openapi examples", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		if err := record(t).WriteOpenAPIExamples(&buf); err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		var doc struct {
+			Paths map[string]map[string]openAPIOperation `json:"paths"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("Error decoding openapi examples: %s", err.Error())
+		}
+		post := doc.Paths["/todo"]["post"]
+		if post.RequestBody == nil {
+			t.Fatalf("Expected request body examples; got %s", buf.String())
+		}
+		if _, ok := post.RequestBody.Content["application/json"].Examples["Create-a-todo"]; !ok {
+			t.Errorf("Expected request example Create-a-todo; got %s", buf.String())
+		}
+		example := post.Responses["201"].Content["application/json"].Examples["Create-a-todo"]
+		if value, ok := example.Value.(map[string]any); !ok || value["name"] != "Get Groceries" {
+			t.Errorf("Expected decoded response example; got %v", example.Value)
+		}
+		if _, ok := doc.Paths["/todo/{id}"]["get"].Responses["200"]; !ok {
+			t.Errorf("Expected example under path template; got %s", buf.String())
+		}
+	})
+	t.Run("examples are added when the test passes", func(t *testing.T) {
+		t.Parallel()
+		docs := NewDocRecorder()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, handler)
+		tester.SetDocRecorder(docs)
+		tester.Get("/todo/1")
+		tester.AddHeader("Authorization", "Bearer secret-token")
+		tester.DocExample("Get a todo")
+		tester.Execute()
+
+		var buf bytes.Buffer
+		if err := docs.WriteMarkdown(&buf); err != nil || buf.Len() > 0 {
+			t.Fatalf("Expected no examples before the test finishes; got %q, %v", buf.String(), err)
+		}
+		mockT.RunCleanups()
+		if err := docs.WriteMarkdown(&buf); err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if !strings.Contains(buf.String(), "Authorization: [REDACTED]\n") || strings.Contains(buf.String(), "secret-token") {
+			t.Errorf("Expected the Authorization header to be redacted; got %s", buf.String())
+		}
+		if tester.exchange.RequestHeader.Get("Authorization") != "Bearer secret-token" {
+			t.Errorf("Expected the recorded exchange to keep its headers; got %q", tester.exchange.RequestHeader.Get("Authorization"))
+		}
+	})
+
+	t.Run("examples of failed tests are discarded", func(t *testing.T) {
+		t.Parallel()
+		docs := NewDocRecorder()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, handler)
+		tester.SetDocRecorder(docs)
+		tester.Get("/todo/1")
+		tester.DocExample("Get a todo")
+		tester.Execute()
+		func() {
+			defer func() { _ = recover() }()
+			tester.AssertStatusCode(http.StatusCreated)
+		}()
+		mockT.RunCleanups()
+
+		var buf bytes.Buffer
+		if err := docs.WriteMarkdown(&buf); err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if buf.Len() > 0 {
+			t.Errorf("Expected no examples from a failed test; got %s", buf.String())
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package httptesting
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// Default options used by Eventually
+const (
+	defaultEventuallyTimeout  = 5 * time.Second
+	defaultEventuallyInterval = 100 * time.Millisecond
+)
+
+// EventuallyOptions options for Eventually
+type EventuallyOptions struct {
+	// Timeout time to keep retrying before failing the test. Defaults to 5s
+	Timeout time.Duration
+
+	// Interval time to wait between attempts. Defaults to 100ms
+	Interval time.Duration
+
+	// Backoff multiplies the interval after every failed attempt. Values less than or equal to 1 keep a constant interval
+	Backoff float64
+
+	// MaxInterval upper bound of the interval when Backoff is set. Zero means no bound
+	MaxInterval time.Duration
+}
+
+// eventuallyT TestingT that records the failure of an attempt instead of failing the test
+type eventuallyT struct {
+	util.TestingT
+	failure string
+}
+
+// Errorf records the failure message
+func (t *eventuallyT) Errorf(format string, args ...any) {
+	t.failure = fmt.Sprintf(format, args...)
+	if t.failure == "" {
+		t.failure = "assertion failed"
+	}
+}
+
+// Fatalf records the failure message and stops the attempt
+func (t *eventuallyT) Fatalf(format string, args ...any) {
+	t.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// Eventually calls attempt until it completes without a failed assertion or the timeout elapses.
+// attempt should build and execute a request and assert its response, e.g. polling a job status URL:
+//
+//	ht.Eventually(httptesting.EventuallyOptions{Timeout: 2 * time.Second}, func() {
+//		ht.Get("/jobs/1")
+//		ht.Execute()
+//		ht.AssertBody([]byte("done"))
+//	})
+//
+// The test fails with the failure of the last attempt and the number of attempts made.
+// Failed attempts are not recorded with the Reporter, only the last exchange and the outcome of Eventually
+func (ht *Httptester) Eventually(opts EventuallyOptions, attempt func()) {
+	ht.t.Helper()
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultEventuallyTimeout
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultEventuallyInterval
+	}
+
+	suite := ht.suite
+	ht.suite = nil
+	start := time.Now()
+	deadline := start.Add(opts.Timeout)
+	interval := opts.Interval
+	attempts := 0
+	var failure string
+	for {
+		attempts++
+		failure = ht.runAttempt(attempt)
+		if failure == "" || !time.Now().Add(interval).Before(deadline) {
+			break
+		}
+		time.Sleep(interval)
+		if opts.Backoff > 1 {
+			interval = time.Duration(float64(interval) * opts.Backoff)
+			if opts.MaxInterval > 0 && interval > opts.MaxInterval {
+				interval = opts.MaxInterval
+			}
+		}
+	}
+	ht.suite = suite
+
+	if suite != nil && ht.requestExecuted && ht.exchange != nil {
+		suite.addExchange(ht.exchange)
+	}
+	defer ht.assertion("Eventually")()
+	if failure != "" {
+		ht.t.Fatalf("Condition not met after %d attempts in %s; last failure: %s", attempts, time.Since(start).Round(time.Millisecond), failure)
+	}
+}
+
+// runAttempt helper function to run a single attempt of Eventually in its own goroutine.
+// Returns the failure message of the attempt, or an empty string if it passed
+func (ht *Httptester) runAttempt(attempt func()) string {
+	t := &eventuallyT{TestingT: ht.t}
+	ht.t = t
+	defer func() { ht.t = t.TestingT }()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if p := recover(); p != nil {
+				t.failure = fmt.Sprintf("panic: %v", p)
+			}
+		}()
+		attempt()
+	}()
+	<-done
+	return t.failure
+}
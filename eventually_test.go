@@ -0,0 +1,118 @@
+package httptesting
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// jobHandler reports a job as pending until it has been polled ready times
+func jobHandler(ready int32) http.Handler {
+	var polls int32
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := "pending"
+		if atomic.AddInt32(&polls, 1) >= ready {
+			status = "done"
+		}
+		_, err := w.Write([]byte(status))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+}
+
+func TestEventually(t *testing.T) {
+	t.Parallel()
+	t.Run("passes once the condition is met", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, jobHandler(3))
+		attempts := 0
+		tester.Eventually(EventuallyOptions{Interval: time.Millisecond}, func() {
+			attempts++
+			tester.Get("/jobs/1")
+			tester.Execute()
+			tester.AssertBody([]byte("done"))
+		})
+		if attempts != 3 {
+			t.Fatalf("Expected 3 attempts; got %d", attempts)
+		}
+		tester.AssertStatusCode(http.StatusOK)
+	})
+
+	t.Run("backoff", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, jobHandler(4))
+		start := time.Now()
+		tester.Eventually(EventuallyOptions{Interval: 5 * time.Millisecond, Backoff: 2, MaxInterval: 10 * time.Millisecond}, func() {
+			tester.Get("/jobs/1")
+			tester.Execute()
+			tester.AssertBody([]byte("done"))
+		})
+		if elapsed := time.Since(start); elapsed < 25*time.Millisecond {
+			t.Fatalf("Expected intervals of 5ms, 10ms and 10ms; finished after %s", elapsed)
+		}
+	})
+
+	t.Run("handler panic is retried", func(t *testing.T) {
+		t.Parallel()
+		var calls int32
+		tester := New(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				panic("not ready")
+			}
+			_, err := w.Write([]byte("Ok"))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		tester.Eventually(EventuallyOptions{Interval: time.Millisecond}, func() {
+			tester.Get("/")
+			tester.Execute()
+			tester.AssertBody([]byte("Ok"))
+		})
+	})
+
+	t.Run("reports last failure and attempts", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, jobHandler(1000))
+
+		defer func() {
+			message, _ := recover().(string)
+			if !strings.Contains(message, "attempts") || !strings.Contains(message, "pending") {
+				t.Fatalf("Expected failure with the attempt count and last failure; got %q", message)
+			}
+		}()
+		tester.Eventually(EventuallyOptions{Timeout: 20 * time.Millisecond, Interval: 5 * time.Millisecond}, func() {
+			tester.Get("/jobs/1")
+			tester.Execute()
+			tester.AssertBody([]byte("done"))
+		})
+	})
+
+	t.Run("failed attempts are not reported", func(t *testing.T) {
+		t.Parallel()
+		reporter := NewReporter("eventually")
+		tester := New(t, jobHandler(3))
+		tester.SetReporter(reporter)
+		tester.Eventually(EventuallyOptions{Interval: time.Millisecond}, func() {
+			tester.Get("/jobs/1")
+			tester.Execute()
+			tester.AssertBody([]byte("done"))
+		})
+
+		var buf bytes.Buffer
+		if err := reporter.WriteJUnit(&buf); err != nil {
+			t.Fatal(err)
+		}
+		report := buf.String()
+		if !strings.Contains(report, `tests="1"`) || !strings.Contains(report, `failures="0"`) || !strings.Contains(report, "PASS Eventually") {
+			t.Fatalf("Expected a single passing case; got %s", report)
+		}
+	})
+}
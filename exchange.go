@@ -0,0 +1,144 @@
+package httptesting
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"unicode/utf8"
+)
+
+// Exchange snapshot of an executed request and the response returned by the handler
+type Exchange struct {
+	// Method http method of the request
+	Method string
+
+	// URL url of the request
+	URL string
+
+	// RequestHeader headers sent with the request
+	RequestHeader http.Header
+
+	// RequestBody body sent with the request
+	RequestBody []byte
+
+	// StatusCode status code of the response
+	StatusCode int
+
+	// ResponseHeader headers of the response
+	ResponseHeader http.Header
+
+	// ResponseBody body of the response
+	ResponseBody []byte
+
+	// Start time the request was dispatched to the handler
+	Start time.Time
+
+	// Duration time the handler took to serve the request
+	Duration time.Duration
+}
+
+// String returns a short summary of the exchange, e.g. "POST /todo -> 201"
+func (e *Exchange) String() string {
+	return fmt.Sprintf("%s %s -> %d", e.Method, e.URL, e.StatusCode)
+}
+
+// exchangeJSON JSON representation of an Exchange. Bodies are stored as text when they are valid UTF-8
+// so recorded exchanges stay readable, and as base64 otherwise
+type exchangeJSON struct {
+	Method             string        `json:"method"`
+	URL                string        `json:"url"`
+	RequestHeader      http.Header   `json:"requestHeader,omitempty"`
+	RequestBody        string        `json:"requestBody,omitempty"`
+	RequestBodyBase64  bool          `json:"requestBodyBase64,omitempty"`
+	StatusCode         int           `json:"statusCode"`
+	ResponseHeader     http.Header   `json:"responseHeader,omitempty"`
+	ResponseBody       string        `json:"responseBody,omitempty"`
+	ResponseBodyBase64 bool          `json:"responseBodyBase64,omitempty"`
+	Start              time.Time     `json:"start"`
+	Duration           time.Duration `json:"duration"`
+}
+
+// encodeBody helper function to encode a body as text or base64
+func encodeBody(body []byte) (string, bool) {
+	if utf8.Valid(body) {
+		return string(body), false
+	}
+	return base64.StdEncoding.EncodeToString(body), true
+}
+
+// decodeBody helper function to decode a body encoded with encodeBody
+func decodeBody(body string, isBase64 bool) ([]byte, error) {
+	if isBase64 {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	if body == "" {
+		return nil, nil
+	}
+	return []byte(body), nil
+}
+
+// MarshalJSON encodes the exchange as JSON with text bodies where possible
+func (e *Exchange) MarshalJSON() ([]byte, error) {
+	v := exchangeJSON{
+		Method:         e.Method,
+		URL:            e.URL,
+		RequestHeader:  e.RequestHeader,
+		StatusCode:     e.StatusCode,
+		ResponseHeader: e.ResponseHeader,
+		Start:          e.Start,
+		Duration:       e.Duration,
+	}
+	v.RequestBody, v.RequestBodyBase64 = encodeBody(e.RequestBody)
+	v.ResponseBody, v.ResponseBodyBase64 = encodeBody(e.ResponseBody)
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes an exchange encoded with MarshalJSON
+func (e *Exchange) UnmarshalJSON(data []byte) error {
+	var v exchangeJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	requestBody, err := decodeBody(v.RequestBody, v.RequestBodyBase64)
+	if err != nil {
+		return err
+	}
+	responseBody, err := decodeBody(v.ResponseBody, v.ResponseBodyBase64)
+	if err != nil {
+		return err
+	}
+	*e = Exchange{
+		Method:         v.Method,
+		URL:            v.URL,
+		RequestHeader:  v.RequestHeader,
+		RequestBody:    requestBody,
+		StatusCode:     v.StatusCode,
+		ResponseHeader: v.ResponseHeader,
+		ResponseBody:   responseBody,
+		Start:          v.Start,
+		Duration:       v.Duration,
+	}
+	return nil
+}
+
+// readRequestBody helper function to read the body of a request before it is executed.
+// The body is replaced with an in-memory copy so the handler can still read it
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Body.Close(); err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	return body, nil
+}
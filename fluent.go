@@ -0,0 +1,197 @@
+package httptesting
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// Fluent chainable variant of the Httptester API where every builder and assertion returns the receiver:
+//
+//	f := httptesting.NewFluent(t, handler)
+//	f.Post("/todo").JSON(todo).Header("Authorization", "Bearer token").
+//		Expect().Status(http.StatusCreated).JSONPath("$.id").Save("id")
+//	f.Get(fmt.Sprintf("/todo/%v", f.Value("id"))).
+//		Expect().Status(http.StatusOK).JSONPath("$.name").Equals(todo.Name)
+//
+// A Fluent shares its state with the Httptester it wraps, so both APIs can be mixed in a test
+type Fluent struct {
+	ht *Httptester
+}
+
+// FluentResponse assertions on the response to the request executed by Expect
+type FluentResponse struct {
+	f *Fluent
+}
+
+// FluentJSONPath assertions on the value at a JSONPath in the response body
+type FluentJSONPath struct {
+	res  *FluentResponse
+	path string
+}
+
+// NewFluent returns a new Fluent httptester. Create a new Fluent for each test for concurrent use
+func NewFluent(t util.TestingT, h http.Handler) *Fluent {
+	return New(t, h).Fluent()
+}
+
+// Fluent returns a Fluent wrapper of the httptester
+func (ht *Httptester) Fluent() *Fluent {
+	return &Fluent{ht: ht}
+}
+
+// Tester returns the Httptester wrapped by the Fluent
+func (f *Fluent) Tester() *Httptester {
+	return f.ht
+}
+
+// Value returns a value saved with SetValue or FluentJSONPath.Save
+func (f *Fluent) Value(key string) any {
+	return f.ht.state.Values[key]
+}
+
+// Request creates a new request with method and url
+func (f *Fluent) Request(method, url string) *Fluent {
+	f.ht.t.Helper()
+	f.ht.NewRequest(method, url, nil)
+	return f
+}
+
+// Get creates a new Get request
+func (f *Fluent) Get(url string) *Fluent {
+	f.ht.t.Helper()
+	return f.Request(http.MethodGet, url)
+}
+
+// Post creates a new Post request. Set its body with Body or JSON
+func (f *Fluent) Post(url string) *Fluent {
+	f.ht.t.Helper()
+	return f.Request(http.MethodPost, url)
+}
+
+// Put creates a new Put request. Set its body with Body or JSON
+func (f *Fluent) Put(url string) *Fluent {
+	f.ht.t.Helper()
+	return f.Request(http.MethodPut, url)
+}
+
+// Patch creates a new Patch request. Set its body with Body or JSON
+func (f *Fluent) Patch(url string) *Fluent {
+	f.ht.t.Helper()
+	return f.Request(http.MethodPatch, url)
+}
+
+// Delete creates a new Delete request
+func (f *Fluent) Delete(url string) *Fluent {
+	f.ht.t.Helper()
+	return f.Request(http.MethodDelete, url)
+}
+
+// Body sets the body of the current request
+func (f *Fluent) Body(reader io.Reader) *Fluent {
+	f.ht.SetBody(reader)
+	return f
+}
+
+// JSON encodes body as JSON, sets it as the body of the current request and sets the Content-Type header
+func (f *Fluent) JSON(body interface{}) *Fluent {
+	f.ht.t.Helper()
+	f.ht.SetRequestBodyJSON(body)
+	f.ht.AddHeader("Content-Type", "application/json")
+	return f
+}
+
+// Header adds a header to the current request
+func (f *Fluent) Header(key, value string) *Fluent {
+	f.ht.AddHeader(key, value)
+	return f
+}
+
+// Cookie adds a cookie to the current request
+func (f *Fluent) Cookie(cookie *http.Cookie) *Fluent {
+	f.ht.AddCookie(cookie)
+	return f
+}
+
+// Expect executes the current request and returns assertions on its response
+func (f *Fluent) Expect() *FluentResponse {
+	f.ht.t.Helper()
+	f.ht.Execute()
+	return &FluentResponse{f: f}
+}
+
+// Then returns the Fluent to build the next request
+func (r *FluentResponse) Then() *Fluent {
+	return r.f
+}
+
+// Status asserts the status code of the response
+func (r *FluentResponse) Status(statusCode int) *FluentResponse {
+	r.f.ht.t.Helper()
+	r.f.ht.AssertStatusCode(statusCode)
+	return r
+}
+
+// Header asserts the value of a header of the response
+func (r *FluentResponse) Header(key, expectedValue string) *FluentResponse {
+	r.f.ht.t.Helper()
+	r.f.ht.AssertHeader(key, expectedValue)
+	return r
+}
+
+// Cookie asserts the response sets a cookie with the expected value
+func (r *FluentResponse) Cookie(cookieName, expectedValue string) *FluentResponse {
+	r.f.ht.t.Helper()
+	r.f.ht.AssertCookieValue(cookieName, expectedValue)
+	return r
+}
+
+// Body asserts the body of the response
+func (r *FluentResponse) Body(expected string) *FluentResponse {
+	r.f.ht.t.Helper()
+	r.f.ht.AssertBody([]byte(expected))
+	return r
+}
+
+// JSON decodes the JSON response body into v and asserts v is deeply equatable to expected
+func (r *FluentResponse) JSON(v interface{}, expected interface{}) *FluentResponse {
+	r.f.ht.t.Helper()
+	r.f.ht.AssertStructDeepEquals(v, expected)
+	return r
+}
+
+// JSONPath returns assertions on the value at a JSONPath in the JSON response body, e.g. "$.items[0].id"
+func (r *FluentResponse) JSONPath(path string) *FluentJSONPath {
+	return &FluentJSONPath{res: r, path: path}
+}
+
+// Exists asserts there is a value at the path
+func (p *FluentJSONPath) Exists() *FluentResponse {
+	p.res.f.ht.t.Helper()
+	p.res.f.ht.AssertJSONPathExists(p.path)
+	return p.res
+}
+
+// NotExists asserts there is no value at the path
+func (p *FluentJSONPath) NotExists() *FluentResponse {
+	p.res.f.ht.t.Helper()
+	p.res.f.ht.AssertJSONPathNotExists(p.path)
+	return p.res
+}
+
+// Equals asserts the value at the path equals expected once encoded as JSON
+func (p *FluentJSONPath) Equals(expected any) *FluentResponse {
+	p.res.f.ht.t.Helper()
+	p.res.f.ht.AssertJSONPathEquals(p.path, expected)
+	return p.res
+}
+
+// Save asserts there is a value at the path and saves it in State.Values under key
+func (p *FluentJSONPath) Save(key string) *FluentResponse {
+	p.res.f.ht.t.Helper()
+	p.res.f.ht.AssertJSONPathExists(p.path)
+	value, _ := p.res.f.ht.jsonPath(p.path)
+	p.res.f.ht.SetValue(key, value)
+	return p.res
+}
@@ -0,0 +1,53 @@
+package httptesting
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+func TestFluent(t *testing.T) {
+	t.Parallel()
+	t.Run("chained requests and assertions", func(t *testing.T) {
+		t.Parallel()
+		f := NewFluent(t, todoHandler())
+		f.Post("/todo").JSON(map[string]string{"name": "Get Groceries"}).Header("Authorization", "Bearer secret").
+			Expect().Status(http.StatusCreated).Header("Content-Type", "application/json").
+			JSONPath("$.id").Save("id").
+			JSONPath("$.name").Equals("Get Groceries").
+			JSONPath("$.done").NotExists().
+			Then().Get(fmt.Sprintf("/todo/%v", f.Value("id"))).
+			Expect().Status(http.StatusOK).Body("Get Groceries")
+	})
+
+	t.Run("body can be read again after JSON", func(t *testing.T) {
+		t.Parallel()
+		f := NewFluent(t, todoHandler())
+		var todo map[string]any
+		f.Post("/todo").Header("Authorization", "Bearer secret").
+			Expect().JSON(&todo, &map[string]any{"id": float64(42), "name": "Get Groceries"}).
+			JSONPath("$.id").Equals(42).
+			Body(`{"id": 42, "name": "Get Groceries"}`)
+	})
+
+	t.Run("shares state with the httptester", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, todoHandler())
+		tester.Fluent().Post("/todo").Header("Authorization", "Bearer secret").Expect().Status(http.StatusCreated)
+		tester.AssertHeader("Content-Type", "application/json")
+		if tester.Fluent().Tester() != tester {
+			t.Fatalf("Expected Tester to return the wrapped httptester")
+		}
+	})
+
+	t.Run("failed assertion fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		f := NewFluent(&mockT, todoHandler())
+
+		defer assertFatal(t)
+		f.Post("/todo").Expect().Status(http.StatusCreated)
+	})
+}
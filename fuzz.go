@@ -0,0 +1,196 @@
+package httptesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	urlpkg "net/url"
+	"strings"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// FuzzOptions options for Fuzz
+type FuzzOptions struct {
+	// Invariants rules checked against the response to every fuzzed request, in addition to the invariants added with AddInvariants
+	Invariants []Invariant
+
+	// AllowServerErrors does not flag 5xx responses when true
+	AllowServerErrors bool
+}
+
+// Fuzz uses the current request as the seed of a native Go fuzz test. The fuzzer mutates the path, query, headers and body
+// of the request, and every field of a JSON body is seeded with edge case values. Each input is executed against the handler
+// and fails if the handler panics, responds with a 5xx status code or violates one of opts.Invariants or the invariants of the httptester:
+//
+//	func FuzzCreateTodo(f *testing.F) {
+//		ht := httptesting.New(f, handler)
+//		ht.Post("/todo", strings.NewReader(`{"title": "write tests"}`))
+//		ht.AddHeader("Content-Type", "application/json")
+//		ht.Fuzz(f, httptesting.FuzzOptions{})
+//	}
+func (ht *Httptester) Fuzz(f *testing.F, opts FuzzOptions) {
+	ht.t.Helper()
+	seed := ht.getRequest()
+	body, err := readRequestBody(seed)
+	if err != nil {
+		ht.t.Fatalf("Error reading request body: %s", err.Error())
+	}
+	method := seed.Method
+	headers := formatFuzzHeaders(seed.Header)
+	f.Add(seed.URL.Path, seed.URL.RawQuery, headers, body)
+	for _, mutation := range jsonMutations(body) {
+		f.Add(seed.URL.Path, seed.URL.RawQuery, headers, mutation)
+	}
+	ht.state.Request = nil
+	opts.Invariants = append(append([]Invariant(nil), ht.invariants...), opts.Invariants...)
+
+	f.Fuzz(func(t *testing.T, path, query, headers string, body []byte) {
+		ht.fuzzTarget(t, opts, method, path, query, headers, body)
+	})
+}
+
+// fuzzTarget helper function to execute a single fuzzed request and check its response
+func (ht *Httptester) fuzzTarget(t util.TestingT, opts FuzzOptions, method, path, query, headers string, body []byte) {
+	t.Helper()
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	req, err := http.NewRequest(method, "/", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Error creating fuzzed request: %s", err.Error())
+	}
+	req.URL = &urlpkg.URL{Path: path, RawQuery: query}
+	req.Header = parseFuzzHeaders(headers)
+	reproducer := fuzzReproducer(req, body)
+
+	tester := New(t, ht.handler)
+	tester.clock = ht.clock
+	tester.state.Request = req
+	tester.ExpectPanic()
+	tester.Execute()
+	if p := tester.state.Panic; p != nil {
+		t.Fatalf("Handler panicked: %v\n%s\n\n%s", p.Value, reproducer, p.Stack)
+	}
+
+	res := tester.state.Response
+	if !opts.AllowServerErrors && res.StatusCode >= 500 {
+		t.Fatalf("Handler responded %d\n%s", res.StatusCode, reproducer)
+	}
+	responseBody := tester.responseBody()
+	for _, invariant := range opts.Invariants {
+		if err := invariant(req, res); err != nil {
+			t.Fatalf("Invariant violated: %s\n%s", err.Error(), reproducer)
+		}
+		res.Body = io.NopCloser(bytes.NewReader(responseBody))
+	}
+}
+
+// formatFuzzHeaders helper function to serialize headers as one "Key: value" line per value, sorted by key
+func formatFuzzHeaders(header http.Header) string {
+	var b strings.Builder
+	for _, key := range sortedKeys(header) {
+		for _, value := range header[key] {
+			fmt.Fprintf(&b, "%s: %s\n", key, value)
+		}
+	}
+	return b.String()
+}
+
+// parseFuzzHeaders helper function to parse headers serialized by formatFuzzHeaders. Lines that are not valid headers are ignored
+func parseFuzzHeaders(headers string) http.Header {
+	header := make(http.Header)
+	for _, line := range strings.Split(headers, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || !validHeaderName(key) || strings.ContainsAny(value, "\r\x00") {
+			continue
+		}
+		header.Add(key, strings.TrimSpace(value))
+	}
+	return header
+}
+
+// validHeaderName returns true if name is a non-empty HTTP token
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if c <= ' ' || c >= 0x7f || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzEdgeCases values each field of a JSON body is replaced with
+var fuzzEdgeCases = []any{nil, "", 0, -1, 1e308, true, []any{}, map[string]any{}, strings.Repeat("a", 1024)}
+
+// jsonMutations returns copies of a JSON body with each field replaced by every edge case value and with each field removed.
+// Returns nil if body is not JSON
+func jsonMutations(body []byte) [][]byte {
+	var root any
+	if len(bytes.TrimSpace(body)) == 0 || json.Unmarshal(body, &root) != nil {
+		return nil
+	}
+	var mutations [][]byte
+	var walk func(v any, replace func(any), remove func())
+	walk = func(v any, replace func(any), remove func()) {
+		for _, edgeCase := range fuzzEdgeCases {
+			replace(edgeCase)
+			mutations = append(mutations, mustMarshal(root))
+		}
+		if remove != nil {
+			remove()
+			mutations = append(mutations, mustMarshal(root))
+		}
+		replace(v)
+
+		switch v := v.(type) {
+		case map[string]any:
+			for _, key := range sortedKeys(v) {
+				value := v[key]
+				walk(value, func(r any) { v[key] = r }, func() { delete(v, key) })
+			}
+		case []any:
+			for i := range v {
+				walk(v[i], func(r any) { v[i] = r }, nil)
+			}
+		}
+	}
+	walk(root, func(r any) { root = r }, nil)
+	return mutations
+}
+
+// mustMarshal helper function to encode a value decoded from JSON
+func mustMarshal(v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// fuzzReproducer returns builder code that reproduces a fuzzed request
+func fuzzReproducer(req *http.Request, body []byte) string {
+	var b strings.Builder
+	b.WriteString("Reproduce with:\n")
+	writeRequestCode(&b, req.Method, req.URL.String(), req.Header, body)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// writeRequestCode helper function to write the builder calls that create and execute a request, one indented call per line
+func writeRequestCode(b *strings.Builder, method, url string, header http.Header, body []byte) {
+	if len(body) == 0 {
+		fmt.Fprintf(b, "\tht.NewRequest(%q, %q, nil)\n", method, url)
+	} else {
+		fmt.Fprintf(b, "\tht.NewRequest(%q, %q, strings.NewReader(%q))\n", method, url, body)
+	}
+	for _, key := range sortedKeys(header) {
+		fmt.Fprintf(b, "\tht.AddHeader(%q, %q)\n", key, header.Get(key))
+	}
+	b.WriteString("\tht.Execute()\n")
+}
@@ -0,0 +1,129 @@
+package httptesting
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// createTodoHandler validates a JSON todo, responding 400 to invalid input
+func createTodoHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var todo struct {
+			Title string `json:"title"`
+			Done  bool   `json:"done"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&todo); err != nil || todo.Title == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, err := w.Write([]byte(`{"title":"` + todo.Title + `"}`))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+}
+
+// hasContentType invariant that successful responses have a Content-Type
+func hasContentType(req *http.Request, res *http.Response) error {
+	if res.StatusCode < 300 && res.Header.Get("Content-Type") == "" {
+		return errors.New("missing Content-Type")
+	}
+	return nil
+}
+
+func FuzzCreateTodo(f *testing.F) {
+	tester := New(f, createTodoHandler())
+	tester.Post("/todo", strings.NewReader(`{"title": "write tests", "done": false}`))
+	tester.AddHeader("Content-Type", "application/json")
+	tester.Fuzz(f, FuzzOptions{Invariants: []Invariant{hasContentType}})
+}
+
+func TestFuzz(t *testing.T) {
+	t.Parallel()
+	t.Run("json mutations", func(t *testing.T) {
+		t.Parallel()
+		mutations := jsonMutations([]byte(`{"title": "a", "tags": ["x"]}`))
+		// root, title, tags and tags[0] are replaced by every edge case and title and tags are removed
+		if expected := 4*len(fuzzEdgeCases) + 2; len(mutations) != expected {
+			t.Fatalf("Expected %d mutations; got %d", expected, len(mutations))
+		}
+		found := false
+		for _, mutation := range mutations {
+			if string(mutation) == `{"tags":["x"],"title":null}` {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("Expected title to be replaced with null; got %q", mutations)
+		}
+		if jsonMutations([]byte("not json")) != nil {
+			t.Fatalf("Expected no mutations of a body that is not JSON")
+		}
+	})
+
+	t.Run("headers round trip", func(t *testing.T) {
+		t.Parallel()
+		header := http.Header{"Accept": {"text/plain", "application/json"}, "X-Id": {"1"}}
+		parsed := parseFuzzHeaders(formatFuzzHeaders(header) + "bad header: x\n: empty\n")
+		if formatFuzzHeaders(parsed) != formatFuzzHeaders(header) {
+			t.Fatalf("Expected %v; got %v", header, parsed)
+		}
+	})
+
+	t.Run("valid input passes", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, createTodoHandler())
+		tester.fuzzTarget(t, FuzzOptions{Invariants: []Invariant{hasContentType}}, http.MethodPost, "todo", "", "", []byte(`{"title": "a"}`))
+	})
+
+	t.Run("panic fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}))
+
+		defer func() {
+			message, _ := recover().(string)
+			if !strings.Contains(message, "boom") || !strings.Contains(message, `ht.NewRequest("GET", "/x?q=1", nil)`) {
+				t.Fatalf("Expected the panic and a reproducer; got %q", message)
+			}
+		}()
+		tester.fuzzTarget(&mockT, FuzzOptions{}, http.MethodGet, "/x", "q=1", "", nil)
+	})
+
+	t.Run("server error fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+
+		defer assertFatal(t)
+		tester.fuzzTarget(&mockT, FuzzOptions{}, http.MethodGet, "/", "", "", nil)
+	})
+
+	t.Run("invariant violation fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte("Ok"))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		tester.fuzzTarget(&mockT, FuzzOptions{AllowServerErrors: true}, http.MethodGet, "/", "", "", nil)
+
+		defer assertFatal(t)
+		tester.fuzzTarget(&mockT, FuzzOptions{Invariants: []Invariant{func(req *http.Request, res *http.Response) error {
+			return errors.New("always fails")
+		}}}, http.MethodGet, "/", "", "", nil)
+	})
+}
@@ -0,0 +1,160 @@
+package httptesting
+
+import (
+	"mime"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// headerValues helper function to get every value of a header of the response to the previous request
+func (ht *Httptester) headerValues(key string) []string {
+	ht.t.Helper()
+	ht.assertRequestExecuted()
+	return ht.state.Response.Header.Values(key)
+}
+
+// AssertHeaderPresent asserts the response to the previous request has at least one value for the header key
+func (ht *Httptester) AssertHeaderPresent(key string) {
+	ht.t.Helper()
+	defer ht.assertion("AssertHeaderPresent")()
+	if len(ht.headerValues(key)) == 0 {
+		ht.t.Fatalf("Expected header %s to be present; got no values", http.CanonicalHeaderKey(key))
+	}
+}
+
+// AssertHeaderAbsent asserts the response to the previous request does not have the header key
+func (ht *Httptester) AssertHeaderAbsent(key string) {
+	ht.t.Helper()
+	defer ht.assertion("AssertHeaderAbsent")()
+	if values := ht.headerValues(key); len(values) > 0 {
+		ht.t.Fatalf("Expected header %s to be absent; got %q", http.CanonicalHeaderKey(key), values)
+	}
+}
+
+// AssertHeaderValues asserts the values of the header key of the response to the previous request are expected, in order
+func (ht *Httptester) AssertHeaderValues(key string, expected ...string) {
+	ht.t.Helper()
+	defer ht.assertion("AssertHeaderValues")()
+	values := ht.headerValues(key)
+	if len(values) != len(expected) || (len(values) > 0 && !reflect.DeepEqual(values, expected)) {
+		ht.t.Fatalf("Expected header %s to be %q; got %q", http.CanonicalHeaderKey(key), expected, values)
+	}
+}
+
+// AssertHeaderMatches asserts at least one value of the header key of the response to the previous request matches the regular expression pattern
+func (ht *Httptester) AssertHeaderMatches(key, pattern string) {
+	ht.t.Helper()
+	defer ht.assertion("AssertHeaderMatches")()
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		ht.t.Fatalf("Error compiling pattern %q: %s", pattern, err.Error())
+	}
+	values := ht.headerValues(key)
+	for _, value := range values {
+		if re.MatchString(value) {
+			return
+		}
+	}
+	ht.t.Fatalf("Expected header %s to match %q; got %q", http.CanonicalHeaderKey(key), pattern, values)
+}
+
+// AssertMediaType asserts the Content-Type of the response to the previous request has the media type, compared case-insensitively,
+// and every parameter in params, e.g. AssertMediaType("application/json", map[string]string{"charset": "utf-8"}).
+// Parameters of the response that are not in params are ignored
+func (ht *Httptester) AssertMediaType(mediaType string, params map[string]string) {
+	ht.t.Helper()
+	defer ht.assertion("AssertMediaType")()
+	values := ht.headerValues("Content-Type")
+	if len(values) == 0 {
+		ht.t.Fatalf("Expected Content-Type %q; got no values", mediaType)
+	}
+	gotType, gotParams, err := mime.ParseMediaType(values[0])
+	if err != nil {
+		ht.t.Fatalf("Error parsing Content-Type %q: %s", values[0], err.Error())
+	}
+	if !strings.EqualFold(gotType, mediaType) {
+		ht.t.Fatalf("Expected Content-Type %q; got %q", mediaType, values)
+	}
+	for _, name := range sortedKeys(params) {
+		got, ok := gotParams[strings.ToLower(name)]
+		want := params[name]
+		if !ok || (got != want && !(strings.EqualFold(name, "charset") && strings.EqualFold(got, want))) {
+			ht.t.Fatalf("Expected Content-Type parameter %s=%q; got %q", name, want, values)
+		}
+	}
+}
+
+// AssertHeaderTokens asserts the comma-separated values of the header key of the response to the previous request
+// contain every expected token, compared case-insensitively, e.g. AssertHeaderTokens("Vary", "Accept-Encoding", "Origin").
+// Tokens may be split across several values of the header
+func (ht *Httptester) AssertHeaderTokens(key string, expected ...string) {
+	ht.t.Helper()
+	defer ht.assertion("AssertHeaderTokens")()
+	values := ht.headerValues(key)
+	tokens := make(map[string]bool)
+	for _, value := range values {
+		for _, token := range strings.Split(value, ",") {
+			tokens[strings.ToLower(strings.TrimSpace(token))] = true
+		}
+	}
+	var missing []string
+	for _, token := range expected {
+		if !tokens[strings.ToLower(strings.TrimSpace(token))] {
+			missing = append(missing, token)
+		}
+	}
+	if len(missing) > 0 {
+		ht.t.Fatalf("Expected header %s to contain %q; missing %q, got %q", http.CanonicalHeaderKey(key), expected, missing, values)
+	}
+}
+
+// AssertHeaderInt asserts the header key of the response to the previous request is the integer expected, e.g. Content-Length.
+// A response without a Content-Length header, such as one written by a handler that only calls Write, has the length of its body
+func (ht *Httptester) AssertHeaderInt(key string, expected int64) {
+	ht.t.Helper()
+	defer ht.assertion("AssertHeaderInt")()
+	if n := ht.headerInt(key); n != expected {
+		ht.t.Fatalf("Expected header %s to be %d; got %q", http.CanonicalHeaderKey(key), expected, ht.headerIntValues(key))
+	}
+}
+
+// AssertHeaderIntBetween asserts the header key of the response to the previous request is an integer between minimum and maximum inclusive
+func (ht *Httptester) AssertHeaderIntBetween(key string, minimum, maximum int64) {
+	ht.t.Helper()
+	defer ht.assertion("AssertHeaderIntBetween")()
+	if n := ht.headerInt(key); n < minimum || n > maximum {
+		ht.t.Fatalf("Expected header %s to be between %d and %d; got %q", http.CanonicalHeaderKey(key), minimum, maximum, ht.headerIntValues(key))
+	}
+}
+
+// headerIntValues helper function to get every value of a numeric header of the response to the previous request.
+// httptest.ResponseRecorder does not set Content-Length, so it falls back to the length of the body when the header is missing
+func (ht *Httptester) headerIntValues(key string) []string {
+	ht.t.Helper()
+	values := ht.headerValues(key)
+	if len(values) > 0 || http.CanonicalHeaderKey(key) != "Content-Length" || ht.stream != nil {
+		return values
+	}
+	n := ht.state.Response.ContentLength
+	if n < 0 {
+		n = int64(len(ht.responseBody()))
+	}
+	return []string{strconv.FormatInt(n, 10)}
+}
+
+// headerInt helper function to parse the single value of a header of the response to the previous request as an integer
+func (ht *Httptester) headerInt(key string) int64 {
+	ht.t.Helper()
+	values := ht.headerIntValues(key)
+	if len(values) != 1 {
+		ht.t.Fatalf("Expected header %s to have a single value; got %q", http.CanonicalHeaderKey(key), values)
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(values[0]), 10, 64)
+	if err != nil {
+		ht.t.Fatalf("Expected header %s to be an integer; got %q", http.CanonicalHeaderKey(key), values)
+	}
+	return n
+}
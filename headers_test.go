@@ -0,0 +1,99 @@
+package httptesting
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// headersHandler responds with multi-value, token list, media type and numeric headers
+func headersHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "Application/JSON; charset=UTF-8; version=2")
+	w.Header().Add("Vary", "Accept-Encoding, Origin")
+	w.Header().Add("Vary", "Cookie")
+	w.Header().Set("Allow", "GET,HEAD,OPTIONS")
+	w.Header().Add("Link", `</todos?page=2>; rel="next"`)
+	w.Header().Add("Link", `</todos?page=9>; rel="last"`)
+	w.Header().Set("X-Request-Id", "req-1234")
+	_, _ = w.Write([]byte("Ok"))
+}
+
+func TestHeaderAssertions(t *testing.T) {
+	t.Parallel()
+	t.Run("passing assertions", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(headersHandler))
+		tester.Get("/todos")
+		tester.Execute()
+		tester.AssertHeaderPresent("x-request-id")
+		tester.AssertHeaderAbsent("Server")
+		tester.AssertHeaderValues("Link", `</todos?page=2>; rel="next"`, `</todos?page=9>; rel="last"`)
+		tester.AssertHeaderValues("Server")
+		tester.AssertHeaderMatches("X-Request-Id", `^req-\d+$`)
+		tester.AssertHeaderMatches("Link", `rel="last"`)
+		tester.AssertMediaType("application/json", map[string]string{"charset": "utf-8", "version": "2"})
+		tester.AssertMediaType("application/json", nil)
+		tester.AssertHeaderTokens("Vary", "origin", "Cookie", "Accept-Encoding")
+		tester.AssertHeaderTokens("Allow", "HEAD", "GET")
+		tester.AssertHeaderInt("Content-Length", 2)
+		tester.AssertHeaderIntBetween("Content-Length", 1, 1024)
+	})
+
+	failures := map[string]func(ht *Httptester){
+		"present":          func(ht *Httptester) { ht.AssertHeaderPresent("Server") },
+		"absent":           func(ht *Httptester) { ht.AssertHeaderAbsent("Vary") },
+		"values order":     func(ht *Httptester) { ht.AssertHeaderValues("Vary", "Cookie", "Accept-Encoding, Origin") },
+		"values count":     func(ht *Httptester) { ht.AssertHeaderValues("Vary", "Cookie") },
+		"matches":          func(ht *Httptester) { ht.AssertHeaderMatches("X-Request-Id", `^\d+$`) },
+		"invalid pattern":  func(ht *Httptester) { ht.AssertHeaderMatches("X-Request-Id", `(`) },
+		"media type":       func(ht *Httptester) { ht.AssertMediaType("text/plain", nil) },
+		"media parameter":  func(ht *Httptester) { ht.AssertMediaType("application/json", map[string]string{"version": "3"}) },
+		"tokens":           func(ht *Httptester) { ht.AssertHeaderTokens("Allow", "GET", "POST") },
+		"int":              func(ht *Httptester) { ht.AssertHeaderInt("Content-Length", 3) },
+		"int not a number": func(ht *Httptester) { ht.AssertHeaderInt("X-Request-Id", 1) },
+		"int missing":      func(ht *Httptester) { ht.AssertHeaderInt("Age", 0) },
+		"int between":      func(ht *Httptester) { ht.AssertHeaderIntBetween("Content-Length", 3, 10) },
+	}
+	for name, assert := range failures {
+		assert := assert
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			defer assertFatal(t)
+			mockT := util.MockTestingT{}
+			tester := New(&mockT, http.HandlerFunc(headersHandler))
+			tester.Get("/todos")
+			tester.Execute()
+			assert(tester)
+		})
+	}
+
+	t.Run("content length of a body written without the header", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("Ok"))
+		}))
+		tester.Get("/todos")
+		tester.Execute()
+		tester.AssertHeaderInt("Content-Length", 2)
+		tester.AssertHeaderIntBetween("content-length", 1, 2)
+		tester.AssertBody([]byte("Ok"))
+	})
+
+	t.Run("messages show every value", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(headersHandler))
+		tester.Get("/todos")
+		tester.Execute()
+
+		defer func() {
+			message, _ := recover().(string)
+			if !strings.Contains(message, `missing ["Host"], got ["Accept-Encoding, Origin" "Cookie"]`) {
+				t.Errorf("Expected failure to show every value; got %q", message)
+			}
+		}()
+		tester.AssertHeaderTokens("vary", "Host")
+	})
+}
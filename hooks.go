@@ -0,0 +1,60 @@
+package httptesting
+
+import (
+	"net/http"
+)
+
+// RequestHook is called with the current request and its body before it is dispatched to the handler.
+// req is State.Request and can be modified, e.g. to add a request ID header or sign the request.
+// A hook that replaces req.Body changes the body sent to the handler and passed to later hooks
+type RequestHook func(ht *Httptester, req *http.Request, body []byte)
+
+// ResponseHook is called with the exchange after the response is recorded.
+// Hooks can make assertions with the Assert* functions of ht or record the exchange, e.g. to check every response has security headers
+type ResponseHook func(ht *Httptester, e *Exchange)
+
+// BeforeRequest registers hooks called before every subsequent request is dispatched to the handler.
+// Hooks are called in the order they were registered, after the cookies of the session are added to the request
+func (ht *Httptester) BeforeRequest(hooks ...RequestHook) {
+	ht.requestHooks = append(ht.requestHooks, hooks...)
+}
+
+// AfterResponse registers hooks called after every subsequent response is recorded.
+// Hooks are called in the order they were registered, after the handler panic check of Execute
+func (ht *Httptester) AfterResponse(hooks ...ResponseHook) {
+	ht.responseHooks = append(ht.responseHooks, hooks...)
+}
+
+// ClearHooks removes every hook registered with BeforeRequest and AfterResponse
+func (ht *Httptester) ClearHooks() {
+	ht.requestHooks = nil
+	ht.responseHooks = nil
+}
+
+// runRequestHooks helper function to call the request hooks. The body is read again after every hook that replaces it,
+// so later hooks are called with the new body. Returns the body sent to the handler
+func (ht *Httptester) runRequestHooks(req *http.Request, body []byte) []byte {
+	ht.t.Helper()
+	for _, hook := range ht.requestHooks {
+		reader := req.Body
+		hook(ht, req, body)
+		if req.Body == reader {
+			continue
+		}
+		var err error
+		body, err = readRequestBody(req)
+		if err != nil {
+			ht.t.Fatalf("Error reading request body: %s", err.Error())
+		}
+	}
+	return body
+}
+
+// runResponseHooks helper function to check the invariants and call the response hooks
+func (ht *Httptester) runResponseHooks(e *Exchange) {
+	ht.t.Helper()
+	ht.checkInvariants(e)
+	for _, hook := range ht.responseHooks {
+		hook(ht, e)
+	}
+}
@@ -0,0 +1,123 @@
+package httptesting
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// signedHandler responds with 401 Unauthorized unless the X-Signature header is the HMAC of the request body
+func signedHandler(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if r.Header.Get("X-Signature") != sign(body) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("X-Request-Id", r.Header.Get("X-Request-Id"))
+	_, _ = w.Write(body)
+}
+
+// sign returns the hex encoded HMAC of body
+func sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestBeforeRequest(t *testing.T) {
+	t.Parallel()
+	t.Run("hooks modify the request in order", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(signedHandler))
+		id := 0
+		tester.BeforeRequest(func(ht *Httptester, req *http.Request, body []byte) {
+			id++
+			req.Header.Set("X-Request-Id", strings.Repeat("a", id))
+		}, func(ht *Httptester, req *http.Request, body []byte) {
+			req.Header.Set("X-Signature", sign(body))
+		})
+		tester.Post("/", strings.NewReader("first"))
+		tester.Execute()
+		tester.AssertStatusCode(http.StatusOK)
+		tester.AssertHeader("X-Request-Id", "a")
+		tester.AssertBody([]byte("first"))
+
+		tester.Post("/", strings.NewReader("second"))
+		tester.Execute()
+		tester.AssertStatusCode(http.StatusOK)
+		tester.AssertHeader("X-Request-Id", "aa")
+	})
+
+	t.Run("hooks replace the body", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(signedHandler))
+		tester.BeforeRequest(func(ht *Httptester, req *http.Request, body []byte) {
+			req.Body = io.NopCloser(bytes.NewReader(bytes.ToUpper(body)))
+		}, func(ht *Httptester, req *http.Request, body []byte) {
+			req.Header.Set("X-Signature", sign(body))
+		})
+		tester.Post("/", strings.NewReader("hello"))
+		tester.Execute()
+		tester.AssertStatusCode(http.StatusOK)
+		tester.AssertBody([]byte("HELLO"))
+		if string(tester.exchange.RequestBody) != "HELLO" {
+			t.Errorf("Expected the replaced body to be recorded; got %q", tester.exchange.RequestBody)
+		}
+	})
+}
+
+func TestAfterResponse(t *testing.T) {
+	t.Parallel()
+	securityHeaders := func(ht *Httptester, e *Exchange) {
+		ht.AssertHeader("X-Content-Type-Options", "nosniff")
+	}
+
+	t.Run("hooks record every exchange", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			_, _ = w.Write([]byte("Ok"))
+		}))
+		var recorded []string
+		tester.AfterResponse(securityHeaders, func(ht *Httptester, e *Exchange) {
+			recorded = append(recorded, e.String())
+		})
+		tester.Get("/a")
+		tester.Execute()
+		tester.Delete("/b")
+		tester.ExecuteStreaming()
+		if strings.Join(recorded, ",") != "GET /a -> 200,DELETE /b -> 200" {
+			t.Errorf("Expected both exchanges to be recorded; got %q", recorded)
+		}
+	})
+
+	t.Run("hooks fail the test", func(t *testing.T) {
+		t.Parallel()
+		defer assertFatal(t)
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("Ok"))
+		}))
+		tester.AfterResponse(securityHeaders)
+		tester.Get("/")
+		tester.Execute()
+	})
+	t.Run("cleared hooks", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("Ok"))
+		}))
+		tester.AfterResponse(securityHeaders)
+		tester.ClearHooks()
+		tester.Get("/")
+		tester.Execute()
+		tester.AssertStatusCode(http.StatusOK)
+	})
+}
@@ -3,11 +3,14 @@ package httptesting
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	urlpkg "net/url"
 	"reflect"
+	"sort"
+	"time"
 
 	"github.com/hunterwilkins2/httptesting/internal/util"
 )
@@ -27,6 +30,9 @@ type State struct {
 
 	// Values key-value store to save values needed later in the test
 	Values map[string]any
+
+	// Panic panic recovered from the handler while serving the previous request, nil if it did not panic
+	Panic *HandlerPanic
 }
 
 // Httptester struct for chaining REST calls together
@@ -42,6 +48,57 @@ type Httptester struct {
 	// and set back to false when a new request is initialized
 	// If Execute() is not called before an assertion is made then the test will fail
 	requestExecuted bool
+
+	// exchange snapshot of the previous request and its response
+	exchange *Exchange
+
+	// suite records exchanges and assertions when a Reporter is set
+	suite *reportSuite
+
+	// failure message of the last failed assertion, used by the Reporter
+	failure string
+
+	// docs captures requests marked with DocExample
+	docs *DocRecorder
+	// docExample documentation example the current request is captured as
+	docExample *docExample
+
+	// cassette records every executed exchange
+	cassette *Cassette
+
+	// verifiers dependencies with expectations asserted by AssertExpectations
+	verifiers []Verifier
+
+	// chunks sent by the previous request executed with ExecuteStreaming
+	chunks []Chunk
+	// flushes number of calls to Flush made by the previous request executed with ExecuteStreaming
+	flushes int
+
+	// requestContext context options of the current request
+	requestContext *requestContext
+	// cancellation records the cancellation of the previous request, nil if its context was not done before the handler returned
+	cancellation *cancellation
+
+	// clock used when waiting between requests and to expire session cookies
+	clock Clock
+	// cookies session jar of cookies set by previous responses
+	cookies []sessionCookie
+
+	// expectPanic is set to true when the current request is expected to make the handler panic
+	expectPanic bool
+
+	// logger logs every executed exchange, nil if logging is disabled
+	logger *logger
+
+	// requestHooks called before every request is dispatched to the handler
+	requestHooks []RequestHook
+	// responseHooks called after every response is recorded
+	responseHooks []ResponseHook
+	// invariants rules checked against every response
+	invariants []Invariant
+
+	// stream handler of the previous request if it was executed with ExecuteSSE
+	stream *streamExecution
 }
 
 // New returns a new httptester. Create a new httptester for each test for concurrent use
@@ -76,6 +133,7 @@ func (ht *Httptester) setBodyReader(reader io.Reader) {
 
 // NewRequest creates a new httptester Request the same as http.NewRequest
 func (ht *Httptester) NewRequest(method string, url string, reader io.Reader) {
+	ht.t.Helper()
 	var err error
 	req := ht.getRequest()
 	req.Method = method
@@ -89,61 +147,72 @@ func (ht *Httptester) NewRequest(method string, url string, reader io.Reader) {
 // NewRequestWithState creates a new httptester Request the same as http.NewRequest.
 // Takes a func of the current state and returns the parameters for a NewRequest
 func (ht *Httptester) NewRequestWithState(f func(s State) (method string, url string, reader io.Reader)) {
+	ht.t.Helper()
 	ht.NewRequest(f(ht.state))
 }
 
 // Get creates a new Get request
 func (ht *Httptester) Get(url string) {
+	ht.t.Helper()
 	ht.NewRequest(http.MethodGet, url, nil)
 }
 
 // GetWithState creates a new Get request.
 // Takes a func of the current state and returns the parameters for a NewRequest
 func (ht *Httptester) GetWithState(f func(s State) (url string)) {
+	ht.t.Helper()
 	ht.Get(f(ht.state))
 }
 
 // Post creates a new Post request with a url and request body
 func (ht *Httptester) Post(url string, reader io.Reader) {
+	ht.t.Helper()
 	ht.NewRequest(http.MethodPost, url, reader)
 }
 
 // PostWithState creates a new Post request with a url and request body
 // Takes a func of the current state and returns the parameters for a NewRequest
 func (ht *Httptester) PostWithState(f func(s State) (url string, reader io.Reader)) {
+	ht.t.Helper()
 	ht.Post(f(ht.state))
 }
 
 // Put creates a new Put request with a url and request body
 func (ht *Httptester) Put(url string, reader io.Reader) {
+	ht.t.Helper()
 	ht.NewRequest(http.MethodPut, url, reader)
 }
 
 // PutWithState creates a new Put request with a url and request body
 // Takes a func of the current state and returns the parameters for a NewRequest
 func (ht *Httptester) PutWithState(f func(s State) (url string, reader io.Reader)) {
+	ht.t.Helper()
 	ht.Put(f(ht.state))
 }
 
 // Patch creates a new Patch request with a url and request body
 func (ht *Httptester) Patch(url string, reader io.Reader) {
+	ht.t.Helper()
 	ht.NewRequest(http.MethodPatch, url, reader)
 }
 
 // PatchWithState creates a new Patch request with a url and request body
 // Takes a func of the current state and returns the parameters for a NewRequest
 func (ht *Httptester) PatchWithState(f func(s State) (url string, reader io.Reader)) {
+	ht.t.Helper()
 	ht.Patch(f(ht.state))
 }
 
 // Delete creates a new Delete request
 func (ht *Httptester) Delete(url string) {
+	ht.t.Helper()
 	ht.NewRequest(http.MethodDelete, url, nil)
 }
 
 // DeleteWithState creates a new Delete request
 // Takes a func of the current state and returns the parameters for a NewRequest
 func (ht *Httptester) DeleteWithState(f func(s State) (url string)) {
+	ht.t.Helper()
 	ht.Delete(f(ht.state))
 }
 
@@ -154,6 +223,7 @@ func (ht *Httptester) SetBody(reader io.Reader) {
 
 // SetRequestBodyJSON encodes the struct passed in as JSON and sets the resulting []byte as the request body
 func (ht *Httptester) SetRequestBodyJSON(body interface{}) {
+	ht.t.Helper()
 	jsonBody, err := util.EncodeJSON(&body)
 	if err != nil {
 		ht.t.Fatalf("Error encoding request body: %s", err.Error())
@@ -202,22 +272,77 @@ func (ht *Httptester) SetValueWithState(f func(s State) (key string, value any))
 
 // Execute executes the current request that was build and resets the state of Response and ResponseResult.
 // This method must be called before any assertions are made.
+// If the handler panics the test fails with the request and the stack trace, unless ExpectPanic was called
 func (ht *Httptester) Execute() {
-	if ht.state.Response != nil {
-		for _, cookie := range ht.state.Response.Cookies() {
-			ht.state.Request.AddCookie(cookie)
-		}
-	}
+	ht.t.Helper()
+	expectPanic := ht.expectPanic
+	req, requestBody, release := ht.prepareRequest()
+	defer release()
+
 	response := httptest.NewRecorder()
-	ht.handler.ServeHTTP(response, ht.getRequest())
+	stopWatch := watchContext(req.Context())
+	start := time.Now()
+	p := ht.serve(response, req)
+	duration := time.Since(start)
+	ht.cancellation = stopWatch()
 
 	ht.requestExecuted = true
 	ht.state.Response = response.Result()
 	ht.state.Request = nil
+	ht.recordExchange(&Exchange{
+		Method:         req.Method,
+		URL:            req.URL.String(),
+		RequestHeader:  req.Header.Clone(),
+		RequestBody:    requestBody,
+		StatusCode:     response.Code,
+		ResponseHeader: ht.state.Response.Header.Clone(),
+		ResponseBody:   response.Body.Bytes(),
+		Start:          start,
+		Duration:       duration,
+	})
+	ht.handlePanic(p, expectPanic, req, requestBody)
+	ht.runResponseHooks(ht.exchange)
+}
+
+// prepareRequest helper function to add the cookies of the session jar to the current request, read its body,
+// call the request hooks and apply its context options. The returned func releases the request context and must be called once the handler returns
+func (ht *Httptester) prepareRequest() (*http.Request, []byte, context.CancelFunc) {
+	ht.t.Helper()
+	req := ht.getRequest()
+	ht.chunks, ht.flushes = nil, 0
+	ht.state.Panic, ht.expectPanic, ht.stream = nil, false, nil
+	ht.addSessionCookies(req)
+	requestBody, err := readRequestBody(req)
+	if err != nil {
+		ht.t.Fatalf("Error reading request body: %s", err.Error())
+	}
+	requestBody = ht.runRequestHooks(req, requestBody)
+	ht.cancellation = nil
+	req, release := ht.applyContext(req)
+	if ht.clock != nil {
+		req = req.WithContext(context.WithValue(req.Context(), clockKey{}, ht.clock))
+	}
+	return req, requestBody, release
+}
+
+// recordExchange helper function to store the previous exchange and its cookies, log it and pass it to the Reporter, DocRecorder and Cassette
+func (ht *Httptester) recordExchange(e *Exchange) {
+	ht.t.Helper()
+	ht.exchange = e
+	ht.logExchange(e)
+	ht.storeCookies(e.ResponseHeader)
+	if ht.suite != nil {
+		ht.suite.addExchange(e)
+	}
+	ht.recordDocExample()
+	if ht.cassette != nil {
+		ht.cassette.add(e)
+	}
 }
 
 // assertRequestExecuted helper fuction to assert the current request was executed
 func (ht *Httptester) assertRequestExecuted() {
+	ht.t.Helper()
 	if !ht.requestExecuted {
 		ht.t.Fatalf("Request %q was not executed", ht.getRequest().URL.String())
 	}
@@ -225,6 +350,8 @@ func (ht *Httptester) assertRequestExecuted() {
 
 // AssertStatus asserts the status of the response to the previous request
 func (ht *Httptester) AssertStatus(expectedStatus string) {
+	ht.t.Helper()
+	defer ht.assertion("AssertStatus")()
 	ht.assertRequestExecuted()
 	if ht.state.Response.Status != expectedStatus {
 		ht.t.Fatalf("Expected status %q; got %q", ht.state.Response.Status, expectedStatus)
@@ -233,6 +360,8 @@ func (ht *Httptester) AssertStatus(expectedStatus string) {
 
 // AssertStatusCode asserts the status code of the response to the previous request
 func (ht *Httptester) AssertStatusCode(statusCode int) {
+	ht.t.Helper()
+	defer ht.assertion("AssertStatusCode")()
 	ht.assertRequestExecuted()
 	if ht.state.Response.StatusCode != statusCode {
 		ht.t.Fatalf("Expected %d; got %d", ht.state.Response.StatusCode, statusCode)
@@ -241,9 +370,11 @@ func (ht *Httptester) AssertStatusCode(statusCode int) {
 
 // AssertHeader asserts the headers of the response to the previous request contains the expected key and value
 func (ht *Httptester) AssertHeader(key, expectedValue string) {
+	ht.t.Helper()
+	defer ht.assertion("AssertHeader")()
 	ht.assertRequestExecuted()
 	if ht.state.Response.Header.Get(key) != expectedValue {
-		ht.t.Fatalf("Expected %q; got %q", ht.state.Response.Header.Get(key), expectedValue)
+		ht.t.Fatalf("Expected header %s to be %q; got %q", http.CanonicalHeaderKey(key), expectedValue, ht.state.Response.Header.Values(key))
 	}
 }
 
@@ -259,6 +390,8 @@ func getCookie(cookies []*http.Cookie, wantCookie string) *http.Cookie {
 
 // AssertCookieExists asserts that a cookie exists in the response to the previous request with the name of cookieName
 func (ht *Httptester) AssertCookieExists(cookieName string) {
+	ht.t.Helper()
+	defer ht.assertion("AssertCookieExists")()
 	ht.assertRequestExecuted()
 	if getCookie(ht.state.Response.Cookies(), cookieName) == nil {
 		ht.t.Fatalf("Expected to find cookie %q", cookieName)
@@ -267,6 +400,8 @@ func (ht *Httptester) AssertCookieExists(cookieName string) {
 
 // AssertCookieValue asserts that a cookie exists and its value is expectedValue in the response to the previous request
 func (ht *Httptester) AssertCookieValue(cookieName, expectedValue string) {
+	ht.t.Helper()
+	defer ht.assertion("AssertCookieValue")()
 	ht.assertRequestExecuted()
 	cookie := getCookie(ht.state.Response.Cookies(), cookieName)
 	if cookie == nil {
@@ -279,6 +414,8 @@ func (ht *Httptester) AssertCookieValue(cookieName, expectedValue string) {
 
 // AssertCookieDeepEquals asserts that a cookie exists and it deep equals expectedCookie in the response to the previous request
 func (ht *Httptester) AssertCookieDeepEquals(expectedCookie *http.Cookie) {
+	ht.t.Helper()
+	defer ht.assertion("AssertCookieDeepEquals")()
 	ht.assertRequestExecuted()
 	if expectedCookie == nil {
 		ht.t.Fatalf("Expected cookie is nil")
@@ -299,13 +436,24 @@ func (ht *Httptester) AssertCookieDeepEquals(expectedCookie *http.Cookie) {
 	}
 }
 
-// AssertBody asserts the body of the response to the previous request matches the []byte provided
-func (ht *Httptester) AssertBody(body []byte) {
-	ht.assertRequestExecuted()
+// responseBody helper function to read the body of the response to the previous request.
+// The body is replaced with an in-memory copy so it can be read again by later assertions
+func (ht *Httptester) responseBody() []byte {
+	ht.t.Helper()
 	resBody, err := io.ReadAll(ht.state.Response.Body)
 	if err != nil {
 		ht.t.Fatalf(err.Error())
 	}
+	ht.state.Response.Body = io.NopCloser(bytes.NewReader(resBody))
+	return resBody
+}
+
+// AssertBody asserts the body of the response to the previous request matches the []byte provided
+func (ht *Httptester) AssertBody(body []byte) {
+	ht.t.Helper()
+	defer ht.assertion("AssertBody")()
+	ht.assertRequestExecuted()
+	resBody := ht.responseBody()
 	if string(resBody) != string(body) {
 		ht.t.Fatalf("Expected %s; got %s", resBody, body)
 	}
@@ -313,8 +461,10 @@ func (ht *Httptester) AssertBody(body []byte) {
 
 // AssertStruct decodes the JSON response body into r and asserts the predicate passed in
 func (ht *Httptester) AssertStruct(r interface{}, predicate func(responseBody interface{}) bool) {
+	ht.t.Helper()
+	defer ht.assertion("AssertStruct")()
 	ht.assertRequestExecuted()
-	err := util.DecodeJSON(ht.state.Response, &r)
+	err := util.DecodeJSON(ht.responseBody(), &r)
 	if err != nil {
 		ht.t.Fatalf("Error parsing response json: %s", err.Error())
 	}
@@ -326,8 +476,10 @@ func (ht *Httptester) AssertStruct(r interface{}, predicate func(responseBody in
 
 // AssertStructDeepEquals decodes the JSON response body into r and asserts r is deeply equatable to expected
 func (ht *Httptester) AssertStructDeepEquals(r interface{}, expected interface{}) {
+	ht.t.Helper()
+	defer ht.assertion("AssertStructDeepEquals")()
 	ht.assertRequestExecuted()
-	err := util.DecodeJSON(ht.state.Response, &r)
+	err := util.DecodeJSON(ht.responseBody(), &r)
 	if err != nil {
 		ht.t.Fatalf("Error parsing response json: %s", err.Error())
 	}
@@ -336,3 +488,13 @@ func (ht *Httptester) AssertStructDeepEquals(r interface{}, expected interface{}
 		ht.t.Fatalf("Expected %v; got %v", expected, r)
 	}
 }
+
+// sortedKeys returns the keys of m in order, so maps are iterated the same way on every run
+func sortedKeys[M ~map[string]V, V any](m M) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,19 +1,167 @@
 package util
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
 
-// TestingT interface to use only Fatalf from testing.T
+// TestingT interface of the methods used from testing.T. Implemented by *testing.T, *testing.B and *testing.F
 type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
 	Fatalf(format string, args ...any)
+	Logf(format string, args ...any)
+	Cleanup(f func())
+	Name() string
+}
+
+// MinimalT interface of implementations that only provide Fatalf
+type MinimalT interface {
+	Fatalf(format string, args ...any)
+}
+
+// Adapt returns t as a TestingT. Methods t does not implement fall back to:
+// Helper does nothing, Errorf calls Fatalf, Logf does nothing, Cleanup discards f and Name returns an empty string
+func Adapt(t MinimalT) TestingT {
+	if full, ok := t.(TestingT); ok {
+		return full
+	}
+	return &adaptedT{t: t}
+}
+
+// adaptedT TestingT wrapping a MinimalT
+type adaptedT struct {
+	t MinimalT
+}
+
+// Helper calls t.Helper if implemented
+func (a *adaptedT) Helper() {
+	if h, ok := a.t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+}
+
+// Errorf calls t.Errorf if implemented, otherwise t.Fatalf
+func (a *adaptedT) Errorf(format string, args ...any) {
+	if e, ok := a.t.(interface{ Errorf(string, ...any) }); ok {
+		e.Errorf(format, args...)
+		return
+	}
+	a.t.Fatalf(format, args...)
+}
+
+// Fatalf calls t.Fatalf
+func (a *adaptedT) Fatalf(format string, args ...any) {
+	a.t.Fatalf(format, args...)
+}
+
+// Logf calls t.Logf if implemented
+func (a *adaptedT) Logf(format string, args ...any) {
+	if l, ok := a.t.(interface{ Logf(string, ...any) }); ok {
+		l.Logf(format, args...)
+	}
+}
+
+// Cleanup calls t.Cleanup if implemented
+func (a *adaptedT) Cleanup(f func()) {
+	if c, ok := a.t.(interface{ Cleanup(func()) }); ok {
+		c.Cleanup(f)
+	}
+}
+
+// Failed returns t.Failed() if implemented, otherwise false
+func (a *adaptedT) Failed() bool {
+	return Failed(a.t)
+}
+
+// Name returns t.Name() if implemented
+func (a *adaptedT) Name() string {
+	if n, ok := a.t.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+	return ""
+}
+
+// Failed returns true if t implements Failed and the test has failed, e.g. *testing.T
+func Failed(t MinimalT) bool {
+	f, ok := t.(interface{ Failed() bool })
+	return ok && f.Failed()
 }
 
 // MockTestingT mock for testing.T
 type MockTestingT struct {
+	mu          sync.Mutex
 	fatalCalled bool
+	errors      []string
+	logs        []string
+	cleanups    []func()
+}
+
+// Helper mock function of testing.T.Helper
+func (t *MockTestingT) Helper() {}
+
+// Errorf mock function of testing.T.Errorf. Records the message without stopping the test
+func (t *MockTestingT) Errorf(format string, args ...any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
 }
 
 // Fatalf mock function of testing.T.Fatalf
 func (t *MockTestingT) Fatalf(format string, args ...any) {
+	t.mu.Lock()
 	t.fatalCalled = true
+	t.mu.Unlock()
 	panic(fmt.Sprintf(format, args...))
 }
+
+// Logf mock function of testing.T.Logf
+func (t *MockTestingT) Logf(format string, args ...any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+
+// Cleanup mock function of testing.T.Cleanup. Registered functions run when RunCleanups is called
+func (t *MockTestingT) Cleanup(f func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cleanups = append(t.cleanups, f)
+}
+
+// Failed mock function of testing.T.Failed. Returns true once Errorf or Fatalf was called
+func (t *MockTestingT) Failed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.fatalCalled || len(t.errors) > 0
+}
+
+// Name mock function of testing.T.Name
+func (t *MockTestingT) Name() string {
+	return "MockTestingT"
+}
+
+// Errors returns the messages passed to Errorf
+func (t *MockTestingT) Errors() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.errors...)
+}
+
+// Logs returns the messages passed to Logf
+func (t *MockTestingT) Logs() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.logs...)
+}
+
+// RunCleanups runs the functions registered with Cleanup in last added, first called order
+func (t *MockTestingT) RunCleanups() {
+	t.mu.Lock()
+	cleanups := t.cleanups
+	t.cleanups = nil
+	t.mu.Unlock()
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
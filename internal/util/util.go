@@ -2,8 +2,8 @@
 package util
 
 import (
+	"bytes"
 	"encoding/json"
-	"net/http"
 )
 
 // EncodeJSON helper function for encoding a struct to JSON
@@ -12,6 +12,6 @@ func EncodeJSON(r interface{}) ([]byte, error) {
 }
 
 // DecodeJSON helper function for decoding a JSON response body into a struct
-func DecodeJSON(w *http.Response, r interface{}) error {
-	return json.NewDecoder(w.Body).Decode(&r)
+func DecodeJSON(body []byte, r interface{}) error {
+	return json.NewDecoder(bytes.NewReader(body)).Decode(&r)
 }
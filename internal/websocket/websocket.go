@@ -0,0 +1,160 @@
+// Package websocket minimal RFC 6455 framing used by httptesting to test handlers that upgrade to WebSocket
+package websocket
+
+import (
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // required by RFC 6455
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Opcodes defined by RFC 6455
+const (
+	OpContinuation byte = 0x0
+	OpText         byte = 0x1
+	OpBinary       byte = 0x2
+	OpClose        byte = 0x8
+	OpPing         byte = 0x9
+	OpPong         byte = 0xA
+)
+
+// Close codes defined by RFC 6455
+const (
+	CloseNormal        = 1000
+	CloseGoingAway     = 1001
+	CloseProtocolError = 1002
+	CloseNoStatus      = 1005
+)
+
+// acceptGUID GUID appended to the client key to compute Sec-WebSocket-Accept
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxPayload largest payload accepted by ReadFrame
+const maxPayload = 32 << 20
+
+// Frame a single WebSocket frame
+type Frame struct {
+	Fin     bool
+	Opcode  byte
+	Payload []byte
+}
+
+// NewKey returns a random Sec-WebSocket-Key
+func NewKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// AcceptKey returns the Sec-WebSocket-Accept value for a Sec-WebSocket-Key
+func AcceptKey(key string) string {
+	h := sha1.New() //nolint:gosec // required by RFC 6455
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// ReadFrame reads a single frame from r, unmasking the payload if it is masked
+func ReadFrame(r io.Reader) (Frame, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Frame{}, err
+	}
+	f := Frame{Fin: header[0]&0x80 != 0, Opcode: header[0] & 0x0F}
+	masked := header[1]&0x80 != 0
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return Frame{}, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return Frame{}, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxPayload {
+		return Frame{}, errors.New("websocket: frame payload too large")
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return Frame{}, err
+		}
+	}
+	f.Payload = make([]byte, length)
+	if _, err := io.ReadFull(r, f.Payload); err != nil {
+		return Frame{}, err
+	}
+	if masked {
+		for i := range f.Payload {
+			f.Payload[i] ^= mask[i%4]
+		}
+	}
+	return f, nil
+}
+
+// WriteFrame writes a single frame to w. Clients must mask every frame they send
+func WriteFrame(w io.Writer, f Frame, masked bool) error {
+	buf := make([]byte, 0, 14+len(f.Payload))
+	first := f.Opcode
+	if f.Fin {
+		first |= 0x80
+	}
+	buf = append(buf, first)
+
+	var maskBit byte
+	if masked {
+		maskBit = 0x80
+	}
+	switch n := len(f.Payload); {
+	case n < 126:
+		buf = append(buf, maskBit|byte(n))
+	case n <= 0xFFFF:
+		buf = append(buf, maskBit|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, maskBit|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+
+	payload := f.Payload
+	if masked {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		buf = append(buf, mask[:]...)
+		payload = make([]byte, len(f.Payload))
+		for i := range f.Payload {
+			payload[i] = f.Payload[i] ^ mask[i%4]
+		}
+	}
+	buf = append(buf, payload...)
+	_, err := w.Write(buf)
+	return err
+}
+
+// ClosePayload returns the payload of a close frame with a status code and reason
+func ClosePayload(code int, reason string) []byte {
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	return append(payload, reason...)
+}
+
+// ParseClosePayload returns the status code and reason of a close frame payload.
+// The code is CloseNoStatus if the payload is empty
+func ParseClosePayload(payload []byte) (int, string) {
+	if len(payload) < 2 {
+		return CloseNoStatus, ""
+	}
+	return int(binary.BigEndian.Uint16(payload)), string(payload[2:])
+}
@@ -0,0 +1,98 @@
+package websocket
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAcceptKey(t *testing.T) {
+	t.Parallel()
+	// example handshake from RFC 6455 section 1.3
+	if got := AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Expected accept key %q; got %q", "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", got)
+	}
+}
+
+func TestFrames(t *testing.T) {
+	t.Parallel()
+	for _, size := range []int{0, 125, 126, 0xFFFF, 0x10000} {
+		for _, masked := range []bool{false, true} {
+			payload := bytes.Repeat([]byte("a"), size)
+			var buf bytes.Buffer
+			if err := WriteFrame(&buf, Frame{Fin: true, Opcode: OpBinary, Payload: payload}, masked); err != nil {
+				t.Fatalf("Unexpected error: %s", err.Error())
+			}
+			wire := buf.Bytes()
+			if wire[0] != 0x80|OpBinary {
+				t.Errorf("Expected fin and opcode byte %#x; got %#x", 0x80|OpBinary, wire[0])
+			}
+			if got := wire[1]&0x80 != 0; got != masked {
+				t.Errorf("Expected mask bit %t for %d bytes; got %t", masked, size, got)
+			}
+			if masked && size > 0 && bytes.Contains(wire, payload) {
+				t.Errorf("Expected masked payload of %d bytes to differ on the wire", size)
+			}
+
+			frame, err := ReadFrame(&buf)
+			if err != nil {
+				t.Fatalf("Unexpected error reading %d bytes: %s", size, err.Error())
+			}
+			if !frame.Fin || frame.Opcode != OpBinary || !bytes.Equal(frame.Payload, payload) {
+				t.Errorf("Expected %d byte frame to round trip; got fin %t, opcode %#x and %d bytes", size, frame.Fin, frame.Opcode, len(frame.Payload))
+			}
+		}
+	}
+}
+
+func TestReadFrame(t *testing.T) {
+	t.Parallel()
+	t.Run("unmasks the example frame", func(t *testing.T) {
+		t.Parallel()
+		// masked "Hello" text frame from RFC 6455 section 5.7
+		frame, err := ReadFrame(bytes.NewReader([]byte{0x81, 0x85, 0x37, 0xfa, 0x21, 0x3d, 0x7f, 0x9f, 0x4d, 0x51, 0x58}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if !frame.Fin || frame.Opcode != OpText || string(frame.Payload) != "Hello" {
+			t.Fatalf("Expected text frame %q; got fin %t, opcode %#x and %q", "Hello", frame.Fin, frame.Opcode, frame.Payload)
+		}
+	})
+
+	t.Run("fragment without fin", func(t *testing.T) {
+		t.Parallel()
+		frame, err := ReadFrame(bytes.NewReader([]byte{0x01, 0x03, 'H', 'e', 'l'}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if frame.Fin || frame.Opcode != OpText || string(frame.Payload) != "Hel" {
+			t.Fatalf("Expected text fragment %q; got fin %t, opcode %#x and %q", "Hel", frame.Fin, frame.Opcode, frame.Payload)
+		}
+	})
+
+	t.Run("payload too large", func(t *testing.T) {
+		t.Parallel()
+		header := []byte{0x82, 127, 0, 0, 0, 0, 0x40, 0, 0, 0}
+		if _, err := ReadFrame(bytes.NewReader(header)); err == nil || !strings.Contains(err.Error(), "too large") {
+			t.Fatalf("Expected payload too large error; got %v", err)
+		}
+	})
+
+	t.Run("truncated frame", func(t *testing.T) {
+		t.Parallel()
+		if _, err := ReadFrame(bytes.NewReader([]byte{0x81, 0x05, 'H', 'i'})); err == nil {
+			t.Fatalf("Expected an error for a truncated payload")
+		}
+	})
+}
+
+func TestClosePayload(t *testing.T) {
+	t.Parallel()
+	code, reason := ParseClosePayload(ClosePayload(CloseGoingAway, "restarting"))
+	if code != CloseGoingAway || reason != "restarting" {
+		t.Errorf("Expected close code %d and reason %q; got %d and %q", CloseGoingAway, "restarting", code, reason)
+	}
+	if code, reason := ParseClosePayload(nil); code != CloseNoStatus || reason != "" {
+		t.Errorf("Expected close code %d for an empty payload; got %d and %q", CloseNoStatus, code, reason)
+	}
+}
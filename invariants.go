@@ -0,0 +1,147 @@
+package httptesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Invariant checks a rule every response must satisfy, returning an error describing the violation
+type Invariant func(req *http.Request, res *http.Response) error
+
+// stackTracePatterns match stack traces of common languages in response bodies
+var stackTracePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`goroutine \d+ \[`),
+	regexp.MustCompile(`\.go:\d+`),
+	regexp.MustCompile(`Traceback \(most recent call last\)`),
+	regexp.MustCompile(`\bat [\w.$<>]+\([\w]+\.(java|kt|scala):\d+\)`),
+	regexp.MustCompile(`\bat .+ \(.+\.[cm]?[jt]s:\d+:\d+\)`),
+	regexp.MustCompile(`\.rb:\d+:in `),
+}
+
+// errStreamedBody returned when an invariant reads the body of a streamed response, which is still being written when invariants are checked
+var errStreamedBody = errors.New("body of a streamed response")
+
+// streamedBody response body passed to invariants for streamed responses
+type streamedBody struct{}
+
+func (streamedBody) Read([]byte) (int, error) { return 0, errStreamedBody }
+
+func (streamedBody) Close() error { return nil }
+
+// AddInvariants adds rules checked against every subsequent response. Execute fails with every violated rule
+// and the request that violated it. Invariants are checked before the hooks registered with AfterResponse,
+// and Fuzz checks them against every fuzzed request. Reading the body of an ExecuteSSE response fails with an error
+// that skips the rule, so rules on the body only apply to responses that are complete
+func (ht *Httptester) AddInvariants(invariants ...Invariant) {
+	ht.invariants = append(ht.invariants, invariants...)
+}
+
+// ClearInvariants removes every invariant added with AddInvariants
+func (ht *Httptester) ClearInvariants() {
+	ht.invariants = nil
+}
+
+// checkInvariants helper function to check the response of an exchange against the invariants of the httptester
+func (ht *Httptester) checkInvariants(e *Exchange) {
+	ht.t.Helper()
+	if len(ht.invariants) == 0 {
+		return
+	}
+	defer ht.assertion("Invariants")()
+	req, err := http.NewRequest(e.Method, e.URL, bytes.NewReader(e.RequestBody))
+	if err != nil {
+		ht.t.Fatalf("Error creating request %s %s: %s", e.Method, e.URL, err.Error())
+	}
+	req.Header = e.RequestHeader.Clone()
+	res := ht.state.Response
+	body := res.Body
+	streamed := ht.stream != nil
+
+	var violations []string
+	for _, invariant := range ht.invariants {
+		if streamed {
+			res.Body = streamedBody{}
+		} else {
+			res.Body = io.NopCloser(bytes.NewReader(e.ResponseBody))
+		}
+		if err := invariant(req, res); err != nil && !errors.Is(err, errStreamedBody) {
+			violations = append(violations, err.Error())
+		}
+	}
+	if streamed {
+		res.Body = body
+	} else {
+		res.Body = io.NopCloser(bytes.NewReader(e.ResponseBody))
+	}
+	if len(violations) > 0 {
+		ht.t.Fatalf("Invariants violated by %s:\n\t%s", e, strings.Join(violations, "\n\t"))
+	}
+}
+
+// HeaderRequired returns an Invariant requiring every response to have the header key, e.g. Content-Type
+func HeaderRequired(key string) Invariant {
+	return func(req *http.Request, res *http.Response) error {
+		if len(res.Header.Values(key)) == 0 {
+			return fmt.Errorf("missing %s header", http.CanonicalHeaderKey(key))
+		}
+		return nil
+	}
+}
+
+// HeaderForbidden returns an Invariant requiring no response to have the header key, e.g. Server or X-Powered-By
+func HeaderForbidden(key string) Invariant {
+	return func(req *http.Request, res *http.Response) error {
+		if values := res.Header.Values(key); len(values) > 0 {
+			return fmt.Errorf("unexpected %s header %q", http.CanonicalHeaderKey(key), values)
+		}
+		return nil
+	}
+}
+
+// NoStackTraces returns an Invariant requiring no response body to contain a Go, Python, Java, JavaScript or Ruby stack trace
+func NoStackTraces() Invariant {
+	return func(req *http.Request, res *http.Response) error {
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		for _, pattern := range stackTracePatterns {
+			if match := pattern.Find(body); match != nil {
+				return fmt.Errorf("response body contains a stack trace: %q", match)
+			}
+		}
+		return nil
+	}
+}
+
+// ErrorBodySchema returns an Invariant requiring the body of every 4xx and 5xx response to be JSON matching schema.
+// Supports the same subset of JSON Schema as Property
+func ErrorBodySchema(schema json.RawMessage) Invariant {
+	s, schemaErr := parseSchema(schema)
+	return func(req *http.Request, res *http.Response) error {
+		if res.StatusCode < 400 {
+			return nil
+		}
+		if schemaErr != nil {
+			return fmt.Errorf("invalid error body schema: %w", schemaErr)
+		}
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		var v any
+		if err := json.Unmarshal(body, &v); err != nil {
+			return fmt.Errorf("error body is not JSON: %q", body)
+		}
+		if problems := s.validate(v, "$"); len(problems) > 0 {
+			return errors.New("error body does not match schema: " + strings.Join(problems, "; "))
+		}
+		return nil
+	}
+}
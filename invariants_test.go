@@ -0,0 +1,154 @@
+package httptesting
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// errorSchema schema of the JSON error bodies returned by apiHandler
+var errorSchema = json.RawMessage(`{"type": "object", "required": ["error"], "properties": {
+	"error": {"type": "object", "required": ["code", "message"], "properties": {
+		"code": {"type": "string"},
+		"message": {"type": "string", "minLength": 1}
+	}}
+}}`)
+
+// apiHandler responds to /ok with JSON, /missing with a JSON error, /leak with a Server header,
+// /crash with a stack trace and /bad with an error body that does not match errorSchema
+func apiHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/ok":
+		_, _ = w.Write([]byte(`{"status": "Ok"}`))
+	case "/missing":
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": {"code": "not_found", "message": "todo not found"}}`))
+	case "/leak":
+		w.Header().Set("Server", "nginx/1.2.3")
+		_, _ = w.Write([]byte(`{"status": "Ok"}`))
+	case "/crash":
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("panic: oops\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:12 +0x1d"))
+	case "/bad":
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": {"code": 400, "message": ""}}`))
+	}
+}
+
+// newInvariantTester returns an httptester with the invariants of apiHandler
+func newInvariantTester(t util.TestingT) *Httptester {
+	tester := New(t, http.HandlerFunc(apiHandler))
+	tester.AddInvariants(HeaderRequired("Content-Type"), HeaderForbidden("Server"), NoStackTraces())
+	tester.AddInvariants(ErrorBodySchema(errorSchema))
+	return tester
+}
+
+func TestAddInvariants(t *testing.T) {
+	t.Parallel()
+	t.Run("responses satisfy invariants", func(t *testing.T) {
+		t.Parallel()
+		tester := newInvariantTester(t)
+		tester.Get("/ok")
+		tester.Execute()
+		tester.AssertStatusCode(http.StatusOK)
+		tester.Get("/missing")
+		tester.Execute()
+		tester.AssertStatusCode(http.StatusNotFound)
+		tester.AssertBody([]byte(`{"error": {"code": "not_found", "message": "todo not found"}}`))
+	})
+
+	for _, path := range []string{"/leak", "/crash", "/bad"} {
+		path := path
+		t.Run("violated by "+path, func(t *testing.T) {
+			t.Parallel()
+			defer assertFatal(t)
+			mockT := util.MockTestingT{}
+			tester := newInvariantTester(&mockT)
+			tester.Get(path)
+			tester.Execute()
+		})
+	}
+
+	t.Run("cleared invariants", func(t *testing.T) {
+		t.Parallel()
+		tester := newInvariantTester(t)
+		tester.ClearInvariants()
+		tester.Get("/leak")
+		tester.Execute()
+		tester.AssertHeader("Server", "nginx/1.2.3")
+	})
+
+	t.Run("failure names the request and every violation", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := newInvariantTester(&mockT)
+
+		defer func() {
+			message, _ := recover().(string)
+			for _, want := range []string{
+				"Invariants violated by POST /crash -> 500:",
+				`response body contains a stack trace: "goroutine 1 ["`,
+				"error body is not JSON",
+			} {
+				if !strings.Contains(message, want) {
+					t.Errorf("Expected failure to contain %q; got %q", want, message)
+				}
+			}
+		}()
+		tester.Post("/crash", strings.NewReader("{}"))
+		tester.Execute()
+	})
+
+	t.Run("error body schema", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(apiHandler))
+		tester.Get("/bad")
+		tester.Execute()
+		err := ErrorBodySchema(errorSchema)(nil, tester.state.Response)
+		if err == nil || !strings.Contains(err.Error(), "$.error.code: expected string; got 400") || !strings.Contains(err.Error(), "$.error.message: expected at least 1 characters; got 0") {
+			t.Errorf("Expected schema violations; got %v", err)
+		}
+	})
+	t.Run("streamed responses skip body invariants", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("event: retry\ndata: later\n\n"))
+			w.(http.Flusher).Flush()
+		}))
+		tester.AddInvariants(HeaderForbidden("Server"), NoStackTraces(), ErrorBodySchema(errorSchema))
+		tester.Get("/events")
+		stream := tester.ExecuteSSE()
+		defer stream.Close()
+		tester.AssertStatusCode(http.StatusServiceUnavailable)
+		stream.AssertEvent(time.Second, "retry", "later")
+	})
+
+	t.Run("streamed responses check header invariants", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Server", "nginx/1.2.3")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.(http.Flusher).Flush()
+		}))
+		tester.AddInvariants(HeaderForbidden("Server"), NoStackTraces(), ErrorBodySchema(errorSchema))
+		defer mockT.RunCleanups()
+		tester.Get("/events")
+
+		defer func() {
+			message, _ := recover().(string)
+			if !strings.Contains(message, `unexpected Server header ["nginx/1.2.3"]`) || strings.Contains(message, "error body") {
+				t.Errorf("Expected only the header violation; got %q", message)
+			}
+		}()
+		tester.ExecuteSSE()
+	})
+}
@@ -0,0 +1,106 @@
+package httptesting
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// parseJSONPath splits a simple JSONPath into object keys and array indices.
+// Supports $.key, $['key'], $["key"] and $[0] segments; the leading $ is optional, e.g. "$.items[0].id" or "items[0].id"
+func parseJSONPath(path string) ([]string, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var segments []string
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: empty key", path)
+			}
+			segments = append(segments, rest[:end])
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: missing ]", path)
+			}
+			segment := rest[1:end]
+			if unquoted, err := strconv.Unquote(strings.ReplaceAll(segment, "'", "\"")); err == nil {
+				segment = unquoted
+			} else if _, err := strconv.Atoi(segment); err != nil {
+				return nil, fmt.Errorf("invalid JSONPath %q: %q is not an index or quoted key", path, segment)
+			}
+			segments = append(segments, segment)
+			rest = rest[end+1:]
+		default:
+			if len(segments) > 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q", path)
+			}
+			rest = "." + rest
+		}
+	}
+	return segments, nil
+}
+
+// jsonPath helper function to find the value at a JSONPath in the JSON body of the response to the previous request
+func (ht *Httptester) jsonPath(path string) (any, bool) {
+	ht.t.Helper()
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		ht.t.Fatalf(err.Error())
+	}
+	var body any
+	if err := json.Unmarshal(ht.responseBody(), &body); err != nil {
+		ht.t.Fatalf("Error parsing response json: %s", err.Error())
+	}
+	return lookupSegments(body, segments)
+}
+
+// AssertJSONPathExists asserts the JSON response body has a value at path, e.g. "$.items[0].id"
+func (ht *Httptester) AssertJSONPathExists(path string) {
+	ht.t.Helper()
+	defer ht.assertion("AssertJSONPathExists")()
+	ht.assertRequestExecuted()
+	if _, ok := ht.jsonPath(path); !ok {
+		ht.t.Fatalf("Expected a value at %s; got %s", path, ht.responseBody())
+	}
+}
+
+// AssertJSONPathNotExists asserts the JSON response body has no value at path
+func (ht *Httptester) AssertJSONPathNotExists(path string) {
+	ht.t.Helper()
+	defer ht.assertion("AssertJSONPathNotExists")()
+	ht.assertRequestExecuted()
+	if value, ok := ht.jsonPath(path); ok {
+		ht.t.Fatalf("Expected no value at %s; got %s", path, jsonString(value))
+	}
+}
+
+// AssertJSONPathEquals asserts the value at path in the JSON response body equals expected once encoded as JSON
+func (ht *Httptester) AssertJSONPathEquals(path string, expected any) {
+	ht.t.Helper()
+	defer ht.assertion("AssertJSONPathEquals")()
+	ht.assertRequestExecuted()
+	value, ok := ht.jsonPath(path)
+	if !ok {
+		ht.t.Fatalf("Expected %s at %s; got no value", jsonString(expected), path)
+	}
+	data, err := json.Marshal(expected)
+	if err != nil {
+		ht.t.Fatalf("Error encoding expected value: %s", err.Error())
+	}
+	var want any
+	if err := json.Unmarshal(data, &want); err != nil {
+		ht.t.Fatalf("Error encoding expected value: %s", err.Error())
+	}
+	if !reflect.DeepEqual(want, value) {
+		ht.t.Fatalf("Expected %s at %s; got %s", data, path, jsonString(value))
+	}
+}
@@ -0,0 +1,82 @@
+package httptesting
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// itemsHandler responds with a JSON document of items
+func itemsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write([]byte(`{"total": 2, "items": [{"id": 1, "tags": ["a"]}, {"id": 2, "tags": []}], "meta.version": "v1"}`))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+}
+
+func TestParseJSONPath(t *testing.T) {
+	t.Parallel()
+	tests := map[string][]string{
+		"$":                   nil,
+		"$.items[0].id":       {"items", "0", "id"},
+		"items[1].tags":       {"items", "1", "tags"},
+		"$['meta.version']":   {"meta.version"},
+		`$["items"][0]["id"]`: {"items", "0", "id"},
+	}
+	for path, expected := range tests {
+		segments, err := parseJSONPath(path)
+		if err != nil {
+			t.Fatalf("Error parsing %q: %s", path, err.Error())
+		}
+		if !reflect.DeepEqual(segments, expected) {
+			t.Fatalf("Expected %q to be %q; got %q", path, expected, segments)
+		}
+	}
+	for _, path := range []string{"$.", "$.items[0", "$[x]"} {
+		if _, err := parseJSONPath(path); err == nil {
+			t.Fatalf("Expected %q to be invalid", path)
+		}
+	}
+}
+
+func TestAssertJSONPath(t *testing.T) {
+	t.Parallel()
+	t.Run("assertions pass", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, itemsHandler())
+		tester.Get("/items")
+		tester.Execute()
+		tester.AssertJSONPathExists("$.items[1].id")
+		tester.AssertJSONPathNotExists("$.items[2]")
+		tester.AssertJSONPathEquals("$.total", 2)
+		tester.AssertJSONPathEquals("$.items[0].tags", []string{"a"})
+		tester.AssertJSONPathEquals("$['meta.version']", "v1")
+	})
+
+	t.Run("missing value fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, itemsHandler())
+		tester.Get("/items")
+		tester.Execute()
+
+		defer assertFatal(t)
+		tester.AssertJSONPathExists("$.items[0].name")
+	})
+
+	t.Run("different value fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, itemsHandler())
+		tester.Get("/items")
+		tester.Execute()
+
+		defer assertFatal(t)
+		tester.AssertJSONPathEquals("$.total", "2")
+	})
+}
@@ -0,0 +1,73 @@
+package httptesting
+
+import (
+	"io"
+	"net/http"
+	urlpkg "net/url"
+	"strings"
+	"time"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// defaultLiveTimeout timeout used for requests to a live server when no http.Client is provided
+const defaultLiveTimeout = 30 * time.Second
+
+// liveHandler http.Handler that forwards each request to a live server and copies the response back
+type liveHandler struct {
+	t       util.TestingT
+	baseURL *urlpkg.URL
+	client  *http.Client
+}
+
+// NewWithBaseURL returns a new httptester that sends requests to a live server at baseURL instead of an in-process handler.
+// Request paths are resolved relative to baseURL. If client is nil a client with a 30 second timeout that does not follow redirects is used
+func NewWithBaseURL(t util.TestingT, baseURL string, client *http.Client) *Httptester {
+	u, err := urlpkg.Parse(baseURL)
+	if err != nil {
+		t.Fatalf("Error parsing base url: %s", err.Error())
+	}
+	if client == nil {
+		client = &http.Client{
+			Timeout: defaultLiveTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+	return New(t, &liveHandler{
+		t:       t,
+		baseURL: u,
+		client:  client,
+	})
+}
+
+// ServeHTTP sends r to the live server and writes the response to w
+func (h *liveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u := *h.baseURL
+	u.Path = strings.TrimSuffix(h.baseURL.Path, "/") + r.URL.Path
+	u.RawPath = ""
+	u.RawQuery = r.URL.RawQuery
+
+	req := r.Clone(r.Context())
+	req.URL = &u
+	req.Host = ""
+	req.RequestURI = ""
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		h.t.Fatalf("Error sending request to %s: %s", u.String(), err.Error())
+		return
+	}
+	defer res.Body.Close()
+
+	for key, values := range res.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(res.StatusCode)
+	if _, err := io.Copy(w, res.Body); err != nil {
+		h.t.Fatalf("Error reading response from %s: %s", u.String(), err.Error())
+	}
+}
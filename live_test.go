@@ -0,0 +1,61 @@
+package httptesting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+func TestNewWithBaseURL(t *testing.T) {
+	t.Parallel()
+	t.Run("requests are sent to the live server", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/todo" || r.URL.Query().Get("page") != "2" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusCreated)
+			_, err := w.Write([]byte("created"))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		defer server.Close()
+
+		tester := NewWithBaseURL(t, server.URL+"/api/", nil)
+		tester.Post("/todo?page=2", strings.NewReader("body"))
+		tester.Execute()
+		tester.AssertStatusCode(http.StatusCreated)
+		tester.AssertHeader("Content-Type", "text/plain")
+		tester.AssertBody([]byte("created"))
+	})
+
+	t.Run("redirects are not followed", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.RedirectHandler("/other", http.StatusFound))
+		defer server.Close()
+
+		tester := NewWithBaseURL(t, server.URL, nil)
+		tester.Get("/")
+		tester.Execute()
+		tester.AssertStatusCode(http.StatusFound)
+		tester.AssertHeader("Location", "/other")
+	})
+
+	t.Run("unreachable server fails test", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		mockT := util.MockTestingT{}
+		tester := NewWithBaseURL(&mockT, server.URL, nil)
+		defer assertFatal(t)
+		tester.Get("/")
+		tester.Execute()
+	})
+}
@@ -0,0 +1,198 @@
+package httptesting
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoadOptions options for ExecuteLoad
+type LoadOptions struct {
+	// Requests total number of requests to send
+	Requests int
+
+	// Concurrency number of requests in flight at the same time. Defaults to GOMAXPROCS
+	Concurrency int
+
+	// Generator returns the i-th request to send. Defaults to copies of the current request.
+	// Generator is never called concurrently, so it can share state between calls
+	Generator func(i int) *http.Request
+}
+
+// LoadResult statistics of the requests sent by ExecuteLoad
+type LoadResult struct {
+	ht *Httptester
+
+	// Requests number of requests sent
+	Requests int
+
+	// StatusCodes number of responses with each status code
+	StatusCodes map[int]int
+
+	// Errors handler panics, in the order they happened
+	Errors []error
+
+	// Latencies time the handler took to serve each request, sorted from fastest to slowest
+	Latencies []time.Duration
+
+	// Duration time taken to send every request
+	Duration time.Duration
+}
+
+// ExecuteLoad sends opts.Requests requests to the handler with opts.Concurrency of them in flight at the same time.
+// Run with -race to detect data races between concurrent requests. The current request is reset and is not recorded
+// as the previous request, so the returned LoadResult is used for assertions instead
+func (ht *Httptester) ExecuteLoad(opts LoadOptions) *LoadResult {
+	ht.t.Helper()
+	if opts.Requests <= 0 {
+		ht.t.Fatalf("Expected a positive number of requests; got %d", opts.Requests)
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = runtime.GOMAXPROCS(0)
+	}
+	generator := opts.Generator
+	if generator == nil {
+		req, requestBody, release := ht.prepareRequest()
+		defer release()
+		generator = func(int) *http.Request {
+			clone := req.Clone(req.Context())
+			clone.Body = io.NopCloser(bytes.NewReader(requestBody))
+			return clone
+		}
+	}
+	ht.state.Request = nil
+
+	type outcome struct {
+		code     int
+		latency  time.Duration
+		panicked any
+	}
+	requests := make(chan *http.Request)
+	outcomes := make(chan outcome, opts.Requests)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for req := range requests {
+				o := outcome{}
+				func() {
+					defer func() { o.panicked = recover() }()
+					response := httptest.NewRecorder()
+					requestStart := time.Now()
+					ht.handler.ServeHTTP(response, req)
+					o.latency = time.Since(requestStart)
+					o.code = response.Code
+				}()
+				outcomes <- o
+			}
+		}()
+	}
+	for i := 0; i < opts.Requests; i++ {
+		requests <- generator(i)
+	}
+	close(requests)
+	wg.Wait()
+	close(outcomes)
+
+	result := &LoadResult{ht: ht, Requests: opts.Requests, StatusCodes: make(map[int]int), Duration: time.Since(start)}
+	for o := range outcomes {
+		if o.panicked != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("handler panicked: %v", o.panicked))
+			continue
+		}
+		result.StatusCodes[o.code]++
+		result.Latencies = append(result.Latencies, o.latency)
+	}
+	sort.Slice(result.Latencies, func(i, j int) bool { return result.Latencies[i] < result.Latencies[j] })
+	return result
+}
+
+// Percentile returns the latency p percent of requests completed within, e.g. Percentile(99) for p99
+func (r *LoadResult) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(r.Latencies))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(r.Latencies) {
+		rank = len(r.Latencies)
+	}
+	return r.Latencies[rank-1]
+}
+
+// String returns a summary of the status codes, errors and latencies
+func (r *LoadResult) String() string {
+	codes := make([]int, 0, len(r.StatusCodes))
+	for code := range r.StatusCodes {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	statuses := make([]string, len(codes))
+	for i, code := range codes {
+		statuses[i] = fmt.Sprintf("%d x%d", code, r.StatusCodes[code])
+	}
+	return fmt.Sprintf("%d requests in %s: status [%s], %d errors, p50 %s, p90 %s, p99 %s",
+		r.Requests, r.Duration, strings.Join(statuses, ", "), len(r.Errors), r.Percentile(50), r.Percentile(90), r.Percentile(99))
+}
+
+// AssertNoErrors asserts the handler did not panic on any request
+func (r *LoadResult) AssertNoErrors() {
+	r.ht.t.Helper()
+	defer r.ht.assertion("AssertNoErrors")()
+	if len(r.Errors) > 0 {
+		r.ht.t.Fatalf("Expected no errors; got %d, first: %s", len(r.Errors), r.Errors[0].Error())
+	}
+}
+
+// AssertNoServerErrors asserts the handler did not panic or respond with a 5xx status code to any request
+func (r *LoadResult) AssertNoServerErrors() {
+	r.ht.t.Helper()
+	defer r.ht.assertion("AssertNoServerErrors")()
+	if len(r.Errors) > 0 {
+		r.ht.t.Fatalf("Expected no server errors; got %d panics, first: %s", len(r.Errors), r.Errors[0].Error())
+	}
+	for code := range r.StatusCodes {
+		if code >= 500 {
+			r.ht.t.Fatalf("Expected no server errors; %s", r)
+		}
+	}
+}
+
+// AssertStatusCode asserts every request was answered with statusCode
+func (r *LoadResult) AssertStatusCode(statusCode int) {
+	r.ht.t.Helper()
+	defer r.ht.assertion("AssertStatusCode")()
+	if r.StatusCodes[statusCode] != r.Requests {
+		r.ht.t.Fatalf("Expected every response to be %d; %s", statusCode, r)
+	}
+}
+
+// AssertStatusCount asserts exactly count requests were answered with statusCode
+func (r *LoadResult) AssertStatusCount(statusCode, count int) {
+	r.ht.t.Helper()
+	defer r.ht.assertion("AssertStatusCount")()
+	if r.StatusCodes[statusCode] != count {
+		r.ht.t.Fatalf("Expected %d responses to be %d; got %d", count, statusCode, r.StatusCodes[statusCode])
+	}
+}
+
+// AssertPercentile asserts p percent of requests completed within maxLatency, e.g. AssertPercentile(99, 50*time.Millisecond)
+func (r *LoadResult) AssertPercentile(p float64, maxLatency time.Duration) {
+	r.ht.t.Helper()
+	defer r.ht.assertion("AssertPercentile")()
+	if latency := r.Percentile(p); latency > maxLatency {
+		r.ht.t.Fatalf("Expected p%g latency to be at most %s; got %s", p, maxLatency, latency)
+	}
+}
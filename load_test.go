@@ -0,0 +1,105 @@
+package httptesting
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// visitHandler counts visits per path, responding 500 to /fail and panicking on /panic
+func visitHandler() http.Handler {
+	var mu sync.Mutex
+	visits := make(map[string]int)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/panic" {
+			panic("boom")
+		}
+		mu.Lock()
+		visits[r.URL.Path]++
+		mu.Unlock()
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, err := w.Write([]byte("Ok"))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+}
+
+func TestExecuteLoad(t *testing.T) {
+	t.Parallel()
+	t.Run("copies of the current request", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, visitHandler())
+		tester.Get("/")
+		result := tester.ExecuteLoad(LoadOptions{Requests: 50, Concurrency: 8})
+		result.AssertNoServerErrors()
+		result.AssertStatusCode(http.StatusOK)
+		result.AssertPercentile(99, time.Second)
+		if len(result.Latencies) != 50 {
+			t.Fatalf("Expected 50 latencies; got %d", len(result.Latencies))
+		}
+	})
+
+	t.Run("generator", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, visitHandler())
+		result := tester.ExecuteLoad(LoadOptions{
+			Requests:    20,
+			Concurrency: 4,
+			Generator: func(i int) *http.Request {
+				path := "/items/" + strconv.Itoa(i)
+				if i%4 == 0 {
+					path = "/fail"
+				}
+				req, _ := http.NewRequest(http.MethodGet, path, nil)
+				return req
+			},
+		})
+		result.AssertNoErrors()
+		result.AssertStatusCount(http.StatusOK, 15)
+		result.AssertStatusCount(http.StatusInternalServerError, 5)
+	})
+
+	t.Run("server errors fail test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, visitHandler())
+		tester.Get("/fail")
+		result := tester.ExecuteLoad(LoadOptions{Requests: 5})
+
+		defer assertFatal(t)
+		result.AssertNoServerErrors()
+	})
+
+	t.Run("panics are counted as errors", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, visitHandler())
+		tester.Get("/panic")
+		result := tester.ExecuteLoad(LoadOptions{Requests: 5, Concurrency: 2})
+		if len(result.Errors) != 5 {
+			t.Fatalf("Expected 5 errors; got %d", len(result.Errors))
+		}
+
+		defer assertFatal(t)
+		result.AssertNoErrors()
+	})
+
+	t.Run("percentile", func(t *testing.T) {
+		t.Parallel()
+		result := &LoadResult{Latencies: []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}}
+		if p := result.Percentile(50); p != 5 {
+			t.Fatalf("Expected p50 of 5; got %d", p)
+		}
+		if p := result.Percentile(99); p != 10 {
+			t.Fatalf("Expected p99 of 10; got %d", p)
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package httptesting
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// LogMode how much of each exchange is logged
+type LogMode int
+
+const (
+	// LogOff disables logging
+	LogOff LogMode = iota
+
+	// LogSummary logs a single line for each exchange, e.g. "POST /todo -> 201 (1.2ms)"
+	LogSummary
+
+	// LogHeaders logs the summary and the request and response headers
+	LogHeaders
+
+	// LogFull logs the summary, headers and request and response bodies
+	LogFull
+)
+
+// defaultMaxLogBody default number of bytes of each body logged in LogFull mode
+const defaultMaxLogBody = 1024
+
+// redactedValue replaces the values of redacted headers
+const redactedValue = "[REDACTED]"
+
+// defaultRedactedHeaders headers redacted when LogOptions.RedactHeaders is nil
+var defaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// LogOptions options for SetLogger
+type LogOptions struct {
+	// Mode how much of each exchange is logged
+	Mode LogMode
+
+	// MaxBodySize number of bytes of each body logged in LogFull mode before it is truncated. Defaults to 1024, negative values log the whole body
+	MaxBodySize int
+
+	// RedactHeaders headers whose values are replaced with [REDACTED].
+	// Defaults to Authorization, Proxy-Authorization, Cookie, Set-Cookie and X-Api-Key when nil, an empty slice redacts nothing
+	RedactHeaders []string
+}
+
+// logger logs the exchanges of an httptester
+type logger struct {
+	mode        LogMode
+	maxBodySize int
+	redact      map[string]bool
+}
+
+// SetLogger logs every subsequent exchange made by the httptester with the Logf of the test,
+// so the exchanges are only shown when the test fails or is run with -v
+func (ht *Httptester) SetLogger(opts LogOptions) {
+	if opts.Mode == LogOff {
+		ht.logger = nil
+		return
+	}
+	if opts.MaxBodySize == 0 {
+		opts.MaxBodySize = defaultMaxLogBody
+	}
+	if opts.RedactHeaders == nil {
+		opts.RedactHeaders = defaultRedactedHeaders
+	}
+	ht.logger = &logger{mode: opts.Mode, maxBodySize: opts.MaxBodySize, redact: redactedSet(opts.RedactHeaders)}
+}
+
+// redactedSet returns the canonical keys of headers as a set
+func redactedSet(headers []string) map[string]bool {
+	redact := make(map[string]bool, len(headers))
+	for _, key := range headers {
+		redact[http.CanonicalHeaderKey(key)] = true
+	}
+	return redact
+}
+
+// redactHeader returns a copy of header with the values of the headers in redact replaced
+func redactHeader(header http.Header, redact map[string]bool) http.Header {
+	redacted := header.Clone()
+	for key, values := range redacted {
+		if !redact[http.CanonicalHeaderKey(key)] {
+			continue
+		}
+		for i := range values {
+			values[i] = redactedValue
+		}
+	}
+	return redacted
+}
+
+// logExchange helper function to log an exchange if a logger is set
+func (ht *Httptester) logExchange(e *Exchange) {
+	ht.t.Helper()
+	if ht.logger == nil {
+		return
+	}
+	ht.t.Logf("%s", ht.logger.format(e))
+}
+
+// format formats an exchange according to the log mode
+func (l *logger) format(e *Exchange) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s)", e, e.Duration.Round(time.Microsecond))
+	if l.mode < LogHeaders {
+		return b.String()
+	}
+	l.writeHeaders(&b, "> ", e.RequestHeader)
+	if l.mode >= LogFull {
+		l.writeBody(&b, "> ", e.RequestBody)
+	}
+	l.writeHeaders(&b, "< ", e.ResponseHeader)
+	if l.mode >= LogFull {
+		l.writeBody(&b, "< ", e.ResponseBody)
+	}
+	return b.String()
+}
+
+// writeHeaders writes each header on its own line sorted by key, with the values of redacted headers replaced
+func (l *logger) writeHeaders(b *strings.Builder, prefix string, header http.Header) {
+	header = redactHeader(header, l.redact)
+	for _, key := range sortedKeys(header) {
+		for _, value := range header[key] {
+			fmt.Fprintf(b, "\n%s%s: %s", prefix, key, value)
+		}
+	}
+}
+
+// writeBody writes a body after a blank line, truncated to the maximum body size. Binary bodies are summarized by their size
+func (l *logger) writeBody(b *strings.Builder, prefix string, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	if !utf8.Valid(body) {
+		fmt.Fprintf(b, "\n%s<%d bytes of binary data>", prefix, len(body))
+		return
+	}
+	truncated := 0
+	if l.maxBodySize >= 0 && len(body) > l.maxBodySize {
+		truncated = len(body) - l.maxBodySize
+		body = body[:l.maxBodySize]
+		for len(body) > 0 && !utf8.Valid(body) {
+			body = body[:len(body)-1]
+			truncated++
+		}
+	}
+	fmt.Fprintf(b, "\n%s", strings.TrimSpace(prefix))
+	for _, line := range strings.Split(string(body), "\n") {
+		fmt.Fprintf(b, "\n%s%s", prefix, line)
+	}
+	if truncated > 0 {
+		fmt.Fprintf(b, "... (%d more bytes)", truncated)
+	}
+}
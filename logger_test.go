@@ -0,0 +1,118 @@
+package httptesting
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// loginHandler sets a session cookie and responds with 201 Created
+func loginHandler(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret-session"})
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write([]byte("Ok"))
+}
+
+func TestSetLogger(t *testing.T) {
+	t.Parallel()
+	t.Run("summary", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(loginHandler))
+		tester.SetLogger(LogOptions{Mode: LogSummary})
+		tester.Post("/login", strings.NewReader(`{"user":"alice"}`))
+		tester.Execute()
+
+		logs := mockT.Logs()
+		if len(logs) != 1 {
+			t.Fatalf("Expected 1 log; got %q", logs)
+		}
+		if !strings.HasPrefix(logs[0], "POST /login -> 201 (") || strings.Contains(logs[0], "\n") {
+			t.Errorf("Expected a single summary line; got %q", logs[0])
+		}
+	})
+
+	t.Run("headers are redacted", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(loginHandler))
+		tester.SetLogger(LogOptions{Mode: LogHeaders})
+		tester.Post("/login", strings.NewReader(`{"user":"alice"}`))
+		tester.AddHeader("Authorization", "Bearer token")
+		tester.AddHeader("X-Request-Id", "1")
+		tester.Execute()
+
+		log := mockT.Logs()[0]
+		for _, want := range []string{"> Authorization: [REDACTED]", "> X-Request-Id: 1", "< Set-Cookie: [REDACTED]", "< Content-Type: text/plain"} {
+			if !strings.Contains(log, want) {
+				t.Errorf("Expected log to contain %q; got %q", want, log)
+			}
+		}
+		for _, secret := range []string{"Bearer token", "secret-session", "alice", "Ok"} {
+			if strings.Contains(log, secret) {
+				t.Errorf("Expected log to not contain %q; got %q", secret, log)
+			}
+		}
+	})
+
+	t.Run("custom redaction", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(loginHandler))
+		tester.SetLogger(LogOptions{Mode: LogHeaders, RedactHeaders: []string{"x-request-id"}})
+		tester.Get("/login")
+		tester.AddHeader("X-Request-Id", "1")
+		tester.Execute()
+
+		log := mockT.Logs()[0]
+		if !strings.Contains(log, "> X-Request-Id: [REDACTED]") || !strings.Contains(log, "secret-session") {
+			t.Errorf("Expected only X-Request-Id to be redacted; got %q", log)
+		}
+	})
+
+	t.Run("full with truncated bodies", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(loginHandler))
+		tester.SetLogger(LogOptions{Mode: LogFull, MaxBodySize: 8})
+		tester.Post("/login", strings.NewReader(`{"user":"alice"}`))
+		tester.Execute()
+
+		log := mockT.Logs()[0]
+		for _, want := range []string{"> {\"user\":... (8 more bytes)", "<\n< Ok"} {
+			if !strings.Contains(log, want) {
+				t.Errorf("Expected log to contain %q; got %q", want, log)
+			}
+		}
+	})
+
+	t.Run("binary body", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(loginHandler))
+		tester.SetLogger(LogOptions{Mode: LogFull})
+		tester.Post("/login", strings.NewReader("\xff\xfe\xfd"))
+		tester.Execute()
+
+		if log := mockT.Logs()[0]; !strings.Contains(log, "> <3 bytes of binary data>") {
+			t.Errorf("Expected binary body to be summarized; got %q", log)
+		}
+	})
+
+	t.Run("off", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(loginHandler))
+		tester.SetLogger(LogOptions{Mode: LogFull})
+		tester.SetLogger(LogOptions{Mode: LogOff})
+		tester.Get("/login")
+		tester.Execute()
+
+		if logs := mockT.Logs(); len(logs) != 0 {
+			t.Errorf("Expected no logs; got %q", logs)
+		}
+	})
+}
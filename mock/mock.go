@@ -0,0 +1,365 @@
+// Package mock stub HTTP server for the outbound dependencies of a handler under test.
+// Register expectations on a Server, point the handler at Server.URL and verify every expectation was met at the end of the test
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// Server local httptest.Server that responds to requests matching registered expectations
+type Server struct {
+	t      util.TestingT
+	server *httptest.Server
+
+	mu           sync.Mutex
+	expectations []*Expectation
+	calls        []Call
+	unexpected   []string
+	outOfOrder   []string
+	ordered      bool
+	lastMatched  int
+}
+
+// Call a request received by the Server
+type Call struct {
+	// Method http method of the request
+	Method string
+
+	// URL path and query of the request
+	URL string
+
+	// Header headers of the request
+	Header http.Header
+
+	// Body body of the request
+	Body []byte
+}
+
+// NewServer starts a new Server. The server is closed when the test finishes
+func NewServer(t util.TestingT) *Server {
+	s := &Server{t: t, lastMatched: -1}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// URL base url of the server, e.g. http://127.0.0.1:54321
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Client returns an http.Client configured to send requests to the server
+func (s *Server) Client() *http.Client {
+	return s.server.Client()
+}
+
+// Close shuts down the server
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// InOrder requires expectations to be matched in the order they were registered
+func (s *Server) InOrder() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ordered = true
+}
+
+// Expect registers an expectation for a request with method and path. By default the expectation must be matched exactly once
+// and responds with 200 OK and an empty body
+func (s *Server) Expect(method, path string) *Expectation {
+	e := &Expectation{
+		server:    s,
+		method:    method,
+		path:      path,
+		header:    make(http.Header),
+		status:    http.StatusOK,
+		resHeader: make(http.Header),
+		times:     1,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expectations = append(s.expectations, e)
+	return e
+}
+
+// Calls returns every request received by the server in order
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// Verify returns an error describing every expectation that was not met, every request that did not match an expectation
+// and every request matched out of order
+func (s *Server) Verify() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var problems []string
+	for _, e := range s.expectations {
+		if e.anyTimes || e.calls == e.times {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("expected %s to be called %d times; called %d times", e, e.times, e.calls))
+	}
+	for _, call := range s.unexpected {
+		problems = append(problems, fmt.Sprintf("unexpected request %s", call))
+	}
+	problems = append(problems, s.outOfOrder...)
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New("mock server " + s.server.URL + ":\n\t" + strings.Join(problems, "\n\t"))
+}
+
+// AssertExpectations fails the test if Verify returns an error
+func (s *Server) AssertExpectations() {
+	s.t.Helper()
+	if err := s.Verify(); err != nil {
+		s.t.Fatalf(err.Error())
+	}
+}
+
+// serveHTTP responds with the first expectation that matches the request and has calls remaining
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	call := Call{Method: r.Method, URL: r.URL.String(), Header: r.Header.Clone(), Body: body}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, call)
+	var matched *Expectation
+	for i, e := range s.expectations {
+		if !e.anyTimes && e.calls >= e.times {
+			continue
+		}
+		if e.matches(r, body) {
+			matched = e
+			e.calls++
+			if s.ordered && i < s.lastMatched {
+				s.outOfOrder = append(s.outOfOrder, fmt.Sprintf("%s %s matched %s out of order", call.Method, call.URL, e))
+			}
+			if i > s.lastMatched {
+				s.lastMatched = i
+			}
+			break
+		}
+	}
+	if matched == nil {
+		s.unexpected = append(s.unexpected, call.Method+" "+call.URL)
+	}
+	s.mu.Unlock()
+
+	if matched == nil {
+		http.Error(w, fmt.Sprintf("mock: no expectation matched %s %s", call.Method, call.URL), http.StatusNotImplemented)
+		return
+	}
+	matched.respond(w, r)
+}
+
+// Expectation an expected request and the response the Server returns for it.
+// Methods return the expectation so they can be chained
+type Expectation struct {
+	server *Server
+
+	method   string
+	path     string
+	query    [][2]string
+	header   http.Header
+	matchers []BodyMatcher
+
+	status    int
+	resHeader http.Header
+	resBody   []byte
+	handler   http.HandlerFunc
+
+	times    int
+	anyTimes bool
+	calls    int
+}
+
+// String describes the expected request, e.g. "GET /users"
+func (e *Expectation) String() string {
+	return e.method + " " + e.path
+}
+
+// WithQuery requires the request to have a query parameter key with value
+func (e *Expectation) WithQuery(key, value string) *Expectation {
+	e.server.mu.Lock()
+	defer e.server.mu.Unlock()
+	e.query = append(e.query, [2]string{key, value})
+	return e
+}
+
+// WithHeader requires the request to have a header key with value
+func (e *Expectation) WithHeader(key, value string) *Expectation {
+	e.server.mu.Lock()
+	defer e.server.mu.Unlock()
+	e.header.Add(key, value)
+	return e
+}
+
+// WithBody requires the request body to satisfy m
+func (e *Expectation) WithBody(m BodyMatcher) *Expectation {
+	e.server.mu.Lock()
+	defer e.server.mu.Unlock()
+	e.matchers = append(e.matchers, m)
+	return e
+}
+
+// Times requires the expectation to be matched exactly n times
+func (e *Expectation) Times(n int) *Expectation {
+	e.server.mu.Lock()
+	defer e.server.mu.Unlock()
+	e.times = n
+	e.anyTimes = false
+	return e
+}
+
+// AnyTimes allows the expectation to be matched any number of times, including never
+func (e *Expectation) AnyTimes() *Expectation {
+	e.server.mu.Lock()
+	defer e.server.mu.Unlock()
+	e.anyTimes = true
+	return e
+}
+
+// Respond sets the status code and body of the response
+func (e *Expectation) Respond(status int, body string) *Expectation {
+	e.server.mu.Lock()
+	defer e.server.mu.Unlock()
+	e.status = status
+	e.resBody = []byte(body)
+	return e
+}
+
+// RespondJSON sets the status code of the response and encodes v as the JSON response body
+func (e *Expectation) RespondJSON(status int, v any) *Expectation {
+	e.server.t.Helper()
+	body, err := util.EncodeJSON(v)
+	if err != nil {
+		e.server.t.Fatalf("Error encoding response body: %s", err.Error())
+	}
+	e.RespondHeader("Content-Type", "application/json")
+	return e.Respond(status, string(body))
+}
+
+// RespondHeader adds a header to the response
+func (e *Expectation) RespondHeader(key, value string) *Expectation {
+	e.server.mu.Lock()
+	defer e.server.mu.Unlock()
+	e.resHeader.Add(key, value)
+	return e
+}
+
+// RespondWith responds to matching requests with a dynamic handler instead of a canned response
+func (e *Expectation) RespondWith(h http.HandlerFunc) *Expectation {
+	e.server.mu.Lock()
+	defer e.server.mu.Unlock()
+	e.handler = h
+	return e
+}
+
+// matches returns true if the request satisfies the expectation. Must be called with the server lock held
+func (e *Expectation) matches(r *http.Request, body []byte) bool {
+	if r.Method != e.method || r.URL.Path != e.path {
+		return false
+	}
+	query := r.URL.Query()
+	for _, kv := range e.query {
+		if !contains(query[kv[0]], kv[1]) {
+			return false
+		}
+	}
+	for key, values := range e.header {
+		for _, value := range values {
+			if !contains(r.Header.Values(key), value) {
+				return false
+			}
+		}
+	}
+	for _, m := range e.matchers {
+		if !m(body) {
+			return false
+		}
+	}
+	return true
+}
+
+// respond writes the canned response or calls the dynamic handler
+func (e *Expectation) respond(w http.ResponseWriter, r *http.Request) {
+	e.server.mu.Lock()
+	handler, status, body := e.handler, e.status, e.resBody
+	header := e.resHeader.Clone()
+	e.server.mu.Unlock()
+
+	if handler != nil {
+		handler(w, r)
+		return
+	}
+	for key, values := range header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// contains helper function to check if values contains value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// BodyMatcher reports whether a request body satisfies an expectation
+type BodyMatcher func(body []byte) bool
+
+// BodyEquals matches bodies equal to expected
+func BodyEquals(expected string) BodyMatcher {
+	return func(body []byte) bool {
+		return string(body) == expected
+	}
+}
+
+// BodyContains matches bodies containing substr
+func BodyContains(substr string) BodyMatcher {
+	return func(body []byte) bool {
+		return bytes.Contains(body, []byte(substr))
+	}
+}
+
+// JSONBodyEquals matches JSON bodies semantically equal to expected once both are encoded as JSON
+func JSONBodyEquals(expected any) BodyMatcher {
+	want, err := util.EncodeJSON(expected)
+	return func(body []byte) bool {
+		if err != nil {
+			return false
+		}
+		var a, b any
+		if json.Unmarshal(want, &a) != nil || json.Unmarshal(body, &b) != nil {
+			return false
+		}
+		return reflect.DeepEqual(a, b)
+	}
+}
@@ -0,0 +1,122 @@
+package mock
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+func send(t *testing.T, s *Server, method, path string, body string, header ...string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, s.URL()+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Error creating request: %s", err.Error())
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	res, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Error sending request: %s", err.Error())
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("Error reading response: %s", err.Error())
+	}
+	return res.StatusCode, string(b)
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	t.Run("matching requests receive canned responses", func(t *testing.T) {
+		t.Parallel()
+		s := NewServer(t)
+		s.Expect(http.MethodGet, "/users").
+			WithQuery("page", "2").
+			WithHeader("Authorization", "Bearer token").
+			RespondJSON(http.StatusOK, []string{"john"})
+		s.Expect(http.MethodPost, "/users").
+			WithBody(JSONBodyEquals(map[string]string{"name": "john"})).
+			Respond(http.StatusCreated, "created")
+
+		if status, body := send(t, s, http.MethodGet, "/users?page=2", "", "Authorization", "Bearer token"); status != http.StatusOK || body != `["john"]` {
+			t.Errorf("Expected 200 [\"john\"]; got %d %s", status, body)
+		}
+		if status, body := send(t, s, http.MethodPost, "/users", `{ "name": "john" }`); status != http.StatusCreated || body != "created" {
+			t.Errorf("Expected 201 created; got %d %s", status, body)
+		}
+		if err := s.Verify(); err != nil {
+			t.Errorf("Unexpected error: %s", err.Error())
+		}
+		if len(s.Calls()) != 2 {
+			t.Errorf("Expected 2 calls; got %d", len(s.Calls()))
+		}
+	})
+
+	t.Run("dynamic responses", func(t *testing.T) {
+		t.Parallel()
+		s := NewServer(t)
+		s.Expect(http.MethodGet, "/echo").AnyTimes().RespondWith(func(w http.ResponseWriter, r *http.Request) {
+			_, err := fmt.Fprint(w, r.URL.Query().Get("q"))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		})
+		for _, q := range []string{"a", "b"} {
+			if _, body := send(t, s, http.MethodGet, "/echo?q="+q, ""); body != q {
+				t.Errorf("Expected %s; got %s", q, body)
+			}
+		}
+		s.AssertExpectations()
+	})
+
+	t.Run("unmet and unexpected requests are reported", func(t *testing.T) {
+		t.Parallel()
+		s := NewServer(t)
+		s.Expect(http.MethodGet, "/users").Times(2)
+		s.Expect(http.MethodPost, "/users").WithBody(BodyContains("john"))
+
+		send(t, s, http.MethodGet, "/users", "")
+		if status, _ := send(t, s, http.MethodPost, "/users", "jane"); status != http.StatusNotImplemented {
+			t.Errorf("Expected %d for unmatched request; got %d", http.StatusNotImplemented, status)
+		}
+
+		err := s.Verify()
+		if err == nil {
+			t.Fatalf("Expected unmet expectations")
+		}
+		for _, want := range []string{"GET /users to be called 2 times; called 1 times", "POST /users to be called 1 times; called 0 times", "unexpected request POST /users"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Expected error to contain %q; got %s", want, err.Error())
+			}
+		}
+
+		mockT := util.MockTestingT{}
+		s.t = &mockT
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected Fatalf to be called during test.")
+			}
+		}()
+		s.AssertExpectations()
+	})
+
+	t.Run("requests out of order are reported", func(t *testing.T) {
+		t.Parallel()
+		s := NewServer(t)
+		s.InOrder()
+		s.Expect(http.MethodPost, "/login").WithBody(BodyEquals("secret"))
+		s.Expect(http.MethodGet, "/profile")
+
+		send(t, s, http.MethodGet, "/profile", "")
+		send(t, s, http.MethodPost, "/login", "secret")
+		if err := s.Verify(); err == nil || !strings.Contains(err.Error(), "out of order") {
+			t.Errorf("Expected out of order error; got %v", err)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package httptesting
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"reflect"
+	"runtime/debug"
+)
+
+// HandlerPanic panic recovered while the handler served a request
+type HandlerPanic struct {
+	// Value value passed to panic
+	Value any
+
+	// Stack stack trace of the goroutine that panicked
+	Stack []byte
+}
+
+// ExpectPanic marks the current request as expected to make the handler panic.
+// Execute records the panic in State.Panic instead of failing the test, to be asserted with AssertPanics or AssertPanicValue
+func (ht *Httptester) ExpectPanic() {
+	ht.getRequest()
+	ht.expectPanic = true
+}
+
+// serve helper function to serve req, recovering a panic from the handler
+func (ht *Httptester) serve(w http.ResponseWriter, req *http.Request) (p *HandlerPanic) {
+	defer func() {
+		if v := recover(); v != nil {
+			p = &HandlerPanic{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	ht.handler.ServeHTTP(w, req)
+	return nil
+}
+
+// handlePanic helper function to record a panic from the handler in State and fail the test with the request that
+// caused it and the stack trace, unless the panic was expected
+func (ht *Httptester) handlePanic(p *HandlerPanic, expected bool, req *http.Request, requestBody []byte) {
+	ht.t.Helper()
+	ht.state.Panic = p
+	if p == nil || expected {
+		return
+	}
+	req.Body = io.NopCloser(bytes.NewReader(requestBody))
+	dump, err := httputil.DumpRequest(req, true)
+	if err != nil {
+		dump = []byte(fmt.Sprintf("%s %s", req.Method, req.URL))
+	}
+	ht.t.Fatalf("Handler panicked: %v\n\nRequest:\n%s\n\n%s", p.Value, bytes.TrimSpace(dump), p.Stack)
+}
+
+// AssertPanics asserts the handler panicked while serving the previous request
+func (ht *Httptester) AssertPanics() {
+	ht.t.Helper()
+	defer ht.assertion("AssertPanics")()
+	ht.assertRequestExecuted()
+	ht.syncStreamPanic(true)
+	if ht.state.Panic == nil {
+		ht.t.Fatalf("Expected handler to panic")
+	}
+}
+
+// AssertPanicValue asserts the handler panicked with a value deeply equal to expected while serving the previous request
+func (ht *Httptester) AssertPanicValue(expected any) {
+	ht.t.Helper()
+	defer ht.assertion("AssertPanicValue")()
+	ht.assertRequestExecuted()
+	ht.syncStreamPanic(true)
+	if ht.state.Panic == nil {
+		ht.t.Fatalf("Expected handler to panic with %v", expected)
+	}
+	if !reflect.DeepEqual(ht.state.Panic.Value, expected) {
+		ht.t.Fatalf("Expected handler to panic with %v; got %v", expected, ht.state.Panic.Value)
+	}
+}
+
+// AssertNoPanic asserts the handler did not panic while serving the previous request
+func (ht *Httptester) AssertNoPanic() {
+	ht.t.Helper()
+	defer ht.assertion("AssertNoPanic")()
+	ht.assertRequestExecuted()
+	ht.syncStreamPanic(false)
+	if ht.state.Panic != nil {
+		ht.t.Fatalf("Expected handler not to panic; got %v\n\n%s", ht.state.Panic.Value, ht.state.Panic.Stack)
+	}
+}
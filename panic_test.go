@@ -0,0 +1,109 @@
+package httptesting
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+var errInvariant = errors.New("invariant broken")
+
+// panicHandler panics with errInvariant on /panic and writes "Ok" otherwise
+func panicHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/panic" {
+			panic(errInvariant)
+		}
+		_, err := w.Write([]byte("Ok"))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+}
+
+func TestPanic(t *testing.T) {
+	t.Parallel()
+	t.Run("unexpected panic fails test with the request and stack", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, panicHandler())
+		tester.Post("/panic", strings.NewReader(`{"name": "john"}`))
+		tester.AddHeader("X-Request-Id", "abc")
+
+		defer func() {
+			message, _ := recover().(string)
+			for _, expected := range []string{"invariant broken", "POST /panic", "X-Request-Id: abc", `{"name": "john"}`, "panic_test.go"} {
+				if !strings.Contains(message, expected) {
+					t.Fatalf("Expected failure to contain %q; got %q", expected, message)
+				}
+			}
+			if tester.state.Panic == nil || tester.state.Panic.Value != errInvariant {
+				t.Fatalf("Expected the panic to be recorded in State")
+			}
+		}()
+		tester.Execute()
+	})
+
+	t.Run("expected panic", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, panicHandler())
+		tester.Get("/panic")
+		tester.ExpectPanic()
+		tester.Execute()
+		tester.AssertPanics()
+		tester.AssertPanicValue(errInvariant)
+
+		tester.Get("/")
+		tester.Execute()
+		tester.AssertNoPanic()
+		tester.AssertBody([]byte("Ok"))
+	})
+
+	t.Run("streaming", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, panicHandler())
+		tester.Get("/panic")
+		tester.ExpectPanic()
+		tester.ExecuteStreaming()
+		tester.AssertPanics()
+	})
+
+	t.Run("AssertPanics fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, panicHandler())
+		tester.Get("/")
+		tester.ExpectPanic()
+		tester.Execute()
+
+		defer assertFatal(t)
+		tester.AssertPanics()
+	})
+
+	t.Run("AssertPanicValue fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, panicHandler())
+		tester.Get("/panic")
+		tester.ExpectPanic()
+		tester.Execute()
+
+		defer assertFatal(t)
+		tester.AssertPanicValue("other")
+	})
+
+	t.Run("AssertNoPanic fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, panicHandler())
+		tester.Get("/panic")
+		tester.ExpectPanic()
+		tester.Execute()
+
+		defer assertFatal(t)
+		tester.AssertNoPanic()
+	})
+}
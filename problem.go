@@ -0,0 +1,234 @@
+package httptesting
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"reflect"
+	"strings"
+)
+
+// problemContentType media type of RFC 7807 problem details documents
+const problemContentType = "application/problem+json"
+
+// defaultProblemType type of a problem details document without a type member
+const defaultProblemType = "about:blank"
+
+// validationErrorMembers members of a problem details document that may hold a list of validation errors
+var validationErrorMembers = []string{"errors", "invalid-params", "invalidParams", "violations"}
+
+// Problem RFC 7807 problem details document
+type Problem struct {
+	// Type URI reference identifying the problem type. Defaults to about:blank when the document has no type member
+	Type string
+
+	// Title short summary of the problem type
+	Title string
+
+	// Status http status code of the response
+	Status int
+
+	// Detail explanation specific to this occurrence of the problem
+	Detail string
+
+	// Instance URI reference identifying this occurrence of the problem
+	Instance string
+
+	// Extensions every other member of the document
+	Extensions map[string]any
+}
+
+// ValidationError a single error in the validation error list of a problem details document
+type ValidationError struct {
+	// Field name or JSON Pointer of the invalid field, e.g. "email", "/email" or "#/email"
+	Field string
+
+	// Message description of the error. An empty Message matches any message
+	Message string
+}
+
+// String returns the validation error as "field: message"
+func (e ValidationError) String() string {
+	return e.Field + ": " + e.Message
+}
+
+// Problem decodes the problem details document in the body of the response to the previous request.
+// Fails the test if the response is not application/problem+json
+func (ht *Httptester) Problem() *Problem {
+	ht.t.Helper()
+	ht.assertRequestExecuted()
+	contentType := ht.state.Response.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != problemContentType {
+		ht.t.Fatalf("Expected Content-Type %q; got %q", problemContentType, contentType)
+	}
+
+	body := ht.responseBody()
+	var members map[string]any
+	if err := json.Unmarshal(body, &members); err != nil {
+		ht.t.Fatalf("Error parsing problem details: %s", err.Error())
+	}
+	p := &Problem{Type: defaultProblemType, Extensions: make(map[string]any)}
+	for key, value := range members {
+		var ok bool
+		switch key {
+		case "type":
+			p.Type, ok = value.(string)
+		case "title":
+			p.Title, ok = value.(string)
+		case "detail":
+			p.Detail, ok = value.(string)
+		case "instance":
+			p.Instance, ok = value.(string)
+		case "status":
+			var status float64
+			status, ok = value.(float64)
+			p.Status = int(status)
+			ok = ok && status == float64(p.Status)
+		default:
+			p.Extensions[key], ok = value, true
+		}
+		if !ok {
+			ht.t.Fatalf("Invalid problem details member %q: %s", key, jsonString(value))
+		}
+	}
+	return p
+}
+
+// AssertProblem asserts the response to the previous request is an application/problem+json document whose status member,
+// if present, matches the status code of the response, and whose members match the non-zero fields of expected.
+// Only the extension members in expected.Extensions are compared
+func (ht *Httptester) AssertProblem(expected Problem) {
+	ht.t.Helper()
+	defer ht.assertion("AssertProblem")()
+	p := ht.Problem()
+
+	var diffs []string
+	if p.Status != 0 && p.Status != ht.state.Response.StatusCode {
+		diffs = append(diffs, fmt.Sprintf("status member %d does not match status code %d", p.Status, ht.state.Response.StatusCode))
+	}
+	compare := func(member string, want, got any, skip bool) {
+		if !skip && !reflect.DeepEqual(want, got) {
+			diffs = append(diffs, fmt.Sprintf("%s: expected %s; got %s", member, jsonString(want), jsonString(got)))
+		}
+	}
+	compare("type", expected.Type, p.Type, expected.Type == "")
+	compare("title", expected.Title, p.Title, expected.Title == "")
+	compare("status", expected.Status, p.Status, expected.Status == 0)
+	compare("detail", expected.Detail, p.Detail, expected.Detail == "")
+	compare("instance", expected.Instance, p.Instance, expected.Instance == "")
+	for _, key := range sortedKeys(expected.Extensions) {
+		got, ok := p.Extensions[key]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("%s: expected %s; got no value", key, jsonString(expected.Extensions[key])))
+			continue
+		}
+		compare(key, normalizeJSON(expected.Extensions[key]), got, false)
+	}
+	if len(diffs) > 0 {
+		ht.t.Fatalf("Problem details do not match:\n\t%s\ngot %s", strings.Join(diffs, "\n\t"), ht.responseBody())
+	}
+}
+
+// AssertProblemExtension asserts the problem details document in the response to the previous request
+// has the extension member key equal to expected once encoded as JSON
+func (ht *Httptester) AssertProblemExtension(key string, expected any) {
+	ht.t.Helper()
+	defer ht.assertion("AssertProblemExtension")()
+	p := ht.Problem()
+	got, ok := p.Extensions[key]
+	if !ok {
+		ht.t.Fatalf("Expected extension member %q; got %s", key, ht.responseBody())
+	}
+	if want := normalizeJSON(expected); !reflect.DeepEqual(want, got) {
+		ht.t.Fatalf("Expected extension member %q to be %s; got %s", key, jsonString(want), jsonString(got))
+	}
+}
+
+// AssertValidationErrors asserts the problem details document in the response to the previous request has exactly the
+// expected validation errors, in any order. The list is read from the errors, invalid-params, invalidParams or violations member.
+// Each entry is an object with the field in a pointer, name, field or param member and the message in a detail, reason or message member
+func (ht *Httptester) AssertValidationErrors(expected ...ValidationError) {
+	ht.t.Helper()
+	defer ht.assertion("AssertValidationErrors")()
+	p := ht.Problem()
+	actual, ok := p.validationErrors()
+	if !ok {
+		ht.t.Fatalf("Expected a list of validation errors in one of the %s members; got %s", strings.Join(validationErrorMembers, ", "), ht.responseBody())
+	}
+
+	unmatched := append([]ValidationError(nil), actual...)
+	var missing []string
+	for _, want := range expected {
+		found := false
+		for i, got := range unmatched {
+			if normalizeField(want.Field) == normalizeField(got.Field) && (want.Message == "" || want.Message == got.Message) {
+				unmatched = append(unmatched[:i], unmatched[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, want.String())
+		}
+	}
+	if len(missing) > 0 || len(unmatched) > 0 {
+		var extra []string
+		for _, e := range unmatched {
+			extra = append(extra, e.String())
+		}
+		var all []string
+		for _, e := range actual {
+			all = append(all, e.String())
+		}
+		ht.t.Fatalf("Validation errors do not match; missing %q, unexpected %q; got %q", missing, extra, all)
+	}
+}
+
+// validationErrors returns the list of validation errors in the document and true if it has one
+func (p *Problem) validationErrors() ([]ValidationError, bool) {
+	for _, member := range validationErrorMembers {
+		list, ok := p.Extensions[member].([]any)
+		if !ok {
+			continue
+		}
+		validation := make([]ValidationError, 0, len(list))
+		for _, item := range list {
+			object, _ := item.(map[string]any)
+			validation = append(validation, ValidationError{
+				Field:   firstString(object, "pointer", "name", "field", "param"),
+				Message: firstString(object, "detail", "reason", "message"),
+			})
+		}
+		return validation, true
+	}
+	return nil, false
+}
+
+// firstString returns the first of keys with a string value in object
+func firstString(object map[string]any, keys ...string) string {
+	for _, key := range keys {
+		if s, ok := object[key].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// normalizeField strips the leading # and / of a JSON Pointer so pointers and field names can be compared
+func normalizeField(field string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(field, "#"), "/")
+}
+
+// normalizeJSON returns v as it would be decoded from its JSON encoding, or v if it cannot be encoded
+func normalizeJSON(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return v
+	}
+	return decoded
+}
@@ -0,0 +1,174 @@
+package httptesting
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// problemHandler responds with problem details documents. /validation has a list of validation errors in the RFC 7807 shape
+// and /mismatch has a status member that does not match the status code
+func problemHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/problem+json; charset=utf-8")
+	switch r.URL.Path {
+	case "/balance":
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{
+			"type": "https://example.com/probs/out-of-credit",
+			"title": "You do not have enough credit.",
+			"status": 403,
+			"detail": "Your current balance is 30, but that costs 50.",
+			"instance": "/account/12345/msgs/abc",
+			"balance": 30,
+			"accounts": ["/account/12345", "/account/67890"]
+		}`))
+	case "/validation":
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{
+			"title": "Your request parameters didn't validate.",
+			"status": 400,
+			"invalid-params": [
+				{"name": "age", "reason": "must be a positive integer"},
+				{"name": "color", "reason": "must be 'green', 'red' or 'blue'"}
+			]
+		}`))
+	case "/pointers":
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"status": 422, "errors": [{"pointer": "#/email", "detail": "is required"}]}`))
+	case "/mismatch":
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"title": "Not Found", "status": 404}`))
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"title": "Not Found", "status": 404}`))
+	}
+}
+
+func TestAssertProblem(t *testing.T) {
+	t.Parallel()
+	t.Run("matches members and extensions", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(problemHandler))
+		tester.Get("/balance")
+		tester.Execute()
+		tester.AssertProblem(Problem{
+			Type:       "https://example.com/probs/out-of-credit",
+			Title:      "You do not have enough credit.",
+			Status:     http.StatusForbidden,
+			Detail:     "Your current balance is 30, but that costs 50.",
+			Extensions: map[string]any{"balance": 30},
+		})
+		tester.AssertProblemExtension("accounts", []string{"/account/12345", "/account/67890"})
+		if p := tester.Problem(); p.Instance != "/account/12345/msgs/abc" {
+			t.Errorf("Expected instance /account/12345/msgs/abc; got %q", p.Instance)
+		}
+	})
+
+	t.Run("type defaults to about:blank", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(problemHandler))
+		tester.Get("/validation")
+		tester.Execute()
+		tester.AssertProblem(Problem{Type: "about:blank", Status: http.StatusBadRequest})
+	})
+
+	t.Run("reports every difference", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(problemHandler))
+		tester.Get("/balance")
+		tester.Execute()
+
+		defer func() {
+			message, _ := recover().(string)
+			for _, want := range []string{`title: expected "Out of credit"; got "You do not have enough credit."`, "balance: expected 50; got 30", "missing: expected true; got no value"} {
+				if !strings.Contains(message, want) {
+					t.Errorf("Expected failure to contain %q; got %q", want, message)
+				}
+			}
+		}()
+		tester.AssertProblem(Problem{Title: "Out of credit", Extensions: map[string]any{"balance": 50, "missing": true}})
+	})
+
+	t.Run("status member must match status code", func(t *testing.T) {
+		t.Parallel()
+		defer assertFatal(t)
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(problemHandler))
+		tester.Get("/mismatch")
+		tester.Execute()
+		tester.AssertProblem(Problem{Title: "Not Found"})
+	})
+
+	t.Run("content type must be problem+json", func(t *testing.T) {
+		t.Parallel()
+		defer assertFatal(t)
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(problemHandler))
+		tester.Get("/")
+		tester.Execute()
+		tester.AssertProblem(Problem{Status: http.StatusNotFound})
+	})
+
+	t.Run("extension value mismatch", func(t *testing.T) {
+		t.Parallel()
+		defer assertFatal(t)
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(problemHandler))
+		tester.Get("/balance")
+		tester.Execute()
+		tester.AssertProblemExtension("balance", 50)
+	})
+}
+
+func TestAssertValidationErrors(t *testing.T) {
+	t.Parallel()
+	t.Run("invalid-params in any order", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(problemHandler))
+		tester.Get("/validation")
+		tester.Execute()
+		tester.AssertValidationErrors(
+			ValidationError{Field: "color"},
+			ValidationError{Field: "age", Message: "must be a positive integer"},
+		)
+	})
+
+	t.Run("errors with pointers", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(problemHandler))
+		tester.Get("/pointers")
+		tester.Execute()
+		tester.AssertValidationErrors(ValidationError{Field: "email", Message: "is required"})
+		tester.AssertValidationErrors(ValidationError{Field: "/email"})
+	})
+
+	t.Run("missing and unexpected errors", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(problemHandler))
+		tester.Get("/validation")
+		tester.Execute()
+
+		defer func() {
+			message, _ := recover().(string)
+			if !strings.Contains(message, `missing ["name: "]`) || !strings.Contains(message, `unexpected ["color: must be 'green', 'red' or 'blue'"]`) {
+				t.Errorf("Expected missing and unexpected errors; got %q", message)
+			}
+		}()
+		tester.AssertValidationErrors(ValidationError{Field: "age"}, ValidationError{Field: "name"})
+	})
+
+	t.Run("no validation errors", func(t *testing.T) {
+		t.Parallel()
+		defer assertFatal(t)
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(problemHandler))
+		tester.Get("/balance")
+		tester.Execute()
+		tester.AssertValidationErrors()
+	})
+}
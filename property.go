@@ -0,0 +1,168 @@
+package httptesting
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Default options used by CheckProperty
+const (
+	defaultPropertyRuns       = 100
+	defaultPropertyMaxShrinks = 1000
+	// maxPropertySize largest size of generated strings and arrays
+	maxPropertySize = 50
+)
+
+// Property an invariant of the API checked against randomly generated inputs
+type Property struct {
+	// Name name of the property used in failure messages, e.g. "POST then GET returns the same resource"
+	Name string
+
+	// Type value of the Go type inputs are generated for, e.g. Todo{}. Check receives values of the same type
+	Type any
+
+	// Schema JSON Schema inputs are generated from when Type is nil. Check receives the decoded JSON value.
+	// Supports type, format, properties, required, items, enum, minimum, maximum, minLength, maxLength, minItems and maxItems
+	Schema json.RawMessage
+
+	// Check executes requests with the input using ht and asserts the responses.
+	// ht is a new httptester for every input, sharing the handler and clock of the httptester CheckProperty is called on
+	Check func(ht *Httptester, input any)
+}
+
+// PropertyOptions options for CheckProperty
+type PropertyOptions struct {
+	// Runs number of inputs to generate. Defaults to 100
+	Runs int
+
+	// Seed seed of the random generator. Defaults to the current time, which is printed on failure to reproduce the run
+	Seed int64
+
+	// MaxShrinks maximum number of smaller inputs tried when shrinking a failing input. Defaults to 1000
+	MaxShrinks int
+}
+
+// CheckProperty checks a property against randomly generated inputs, growing in size with each run.
+// When an input fails, it is shrunk to the smallest input that still fails, and the test fails with that input
+// and the requests made for it as builder code:
+//
+//	ht.CheckProperty(httptesting.Property{
+//		Name: "POST then GET returns the same resource",
+//		Type: Todo{},
+//		Check: func(ht *httptesting.Httptester, input any) {
+//			ht.Post("/todo", nil)
+//			ht.SetRequestBodyJSON(input)
+//			ht.Execute()
+//			ht.AssertStatusCode(http.StatusCreated)
+//			...
+//		},
+//	}, httptesting.PropertyOptions{})
+func (ht *Httptester) CheckProperty(p Property, opts PropertyOptions) {
+	ht.t.Helper()
+	defer ht.assertion("CheckProperty")()
+	if opts.Runs <= 0 {
+		opts.Runs = defaultPropertyRuns
+	}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	if opts.MaxShrinks <= 0 {
+		opts.MaxShrinks = defaultPropertyMaxShrinks
+	}
+
+	schema, decode := ht.propertySchema(p)
+	r := rand.New(rand.NewSource(opts.Seed))
+	for run := 0; run < opts.Runs; run++ {
+		size := 1 + run*maxPropertySize/opts.Runs
+		value := schema.generate(r, size)
+		failure, _ := ht.checkInput(p, decode, value)
+		if failure == "" {
+			continue
+		}
+
+		shrinks := 0
+		for shrunk := true; shrunk && shrinks < opts.MaxShrinks; {
+			shrunk = false
+			for _, candidate := range schema.shrink(value) {
+				if shrinks++; shrinks > opts.MaxShrinks {
+					break
+				}
+				if f, _ := ht.checkInput(p, decode, candidate); f != "" {
+					value, failure, shrunk = candidate, f, true
+					break
+				}
+			}
+		}
+		_, exchanges := ht.checkInput(p, decode, value)
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "Property %q failed after %d runs (seed %d)\n", p.Name, run+1, opts.Seed)
+		fmt.Fprintf(&b, "Input: %s\n", mustMarshal(value))
+		fmt.Fprintf(&b, "Failure: %s\n", failure)
+		b.WriteString("Reproduce with:\n")
+		for _, e := range exchanges {
+			header := e.RequestHeader.Clone()
+			header.Del("Cookie")
+			writeRequestCode(&b, e.Method, e.URL, header, e.RequestBody)
+		}
+		ht.t.Fatalf("%s", strings.TrimSuffix(b.String(), "\n"))
+	}
+}
+
+// propertySchema helper function to get the schema inputs of a property are generated from
+// and a func to decode generated values into the input passed to Check
+func (ht *Httptester) propertySchema(p Property) (*jsonSchema, func(any) (any, error)) {
+	ht.t.Helper()
+	if p.Check == nil {
+		ht.t.Fatalf("Property %q has no Check", p.Name)
+	}
+	if p.Type != nil {
+		t := reflect.TypeOf(p.Type)
+		schema, err := schemaForType(t)
+		if err == nil {
+			err = schema.check("$")
+		}
+		if err != nil {
+			ht.t.Fatalf("Error generating inputs for property %q: %s", p.Name, err.Error())
+		}
+		return schema, func(value any) (any, error) {
+			input := reflect.New(t)
+			if err := json.Unmarshal(mustMarshal(value), input.Interface()); err != nil {
+				return nil, err
+			}
+			return input.Elem().Interface(), nil
+		}
+	}
+	if len(p.Schema) == 0 {
+		ht.t.Fatalf("Property %q needs a Type or Schema", p.Name)
+	}
+	schema, err := parseSchema(p.Schema)
+	if err != nil {
+		ht.t.Fatalf("Error parsing schema of property %q: %s", p.Name, err.Error())
+	}
+	if err := schema.check("$"); err != nil {
+		ht.t.Fatalf("Error generating inputs for property %q: %s", p.Name, err.Error())
+	}
+	return schema, func(value any) (any, error) {
+		return value, nil
+	}
+}
+
+// checkInput helper function to run the check of a property with a single input on a new httptester.
+// Returns the failure message, empty if the check passed, and the exchanges made
+func (ht *Httptester) checkInput(p Property, decode func(any) (any, error), value any) (string, []*Exchange) {
+	input, err := decode(value)
+	if err != nil {
+		return fmt.Sprintf("Error decoding input into %T: %s", p.Type, err.Error()), nil
+	}
+	tester := New(ht.t, ht.handler)
+	tester.clock = ht.clock
+	cassette := NewCassette()
+	tester.Record(cassette)
+	failure := tester.runAttempt(func() { p.Check(tester, input) })
+	return failure, cassette.Exchanges()
+}
@@ -0,0 +1,223 @@
+package httptesting
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+type note struct {
+	Title    string   `json:"title"`
+	Tags     []string `json:"tags,omitempty"`
+	Priority int8     `json:"priority"`
+}
+
+// noteHandler stores notes in memory. Titles longer than maxTitle are truncated when stored
+func noteHandler(maxTitle int) http.Handler {
+	var mu sync.Mutex
+	var notes []note
+	mux := http.NewServeMux()
+	mux.HandleFunc("/notes", func(w http.ResponseWriter, r *http.Request) {
+		var n note
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if runes := []rune(n.Title); len(runes) > maxTitle {
+			n.Title = string(runes[:maxTitle])
+		}
+		mu.Lock()
+		notes = append(notes, n)
+		id := len(notes) - 1
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		_, err := w.Write([]byte(`{"id":` + strconv.Itoa(id) + `}`))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	mux.HandleFunc("/notes/", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/notes/"))
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil || id < 0 || id >= len(notes) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		body, err := json.Marshal(notes[id])
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, err = w.Write(body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	return mux
+}
+
+// label text type decoded with UnmarshalText, generated from any string
+type label struct {
+	text string
+}
+
+func (l *label) UnmarshalText(text []byte) error {
+	l.text = string(text)
+	return nil
+}
+
+// uuidLike identifier that only decodes from 32 hexadecimal digits
+type uuidLike [16]byte
+
+func (u *uuidLike) UnmarshalText(text []byte) error {
+	if len(text) != 2*len(u) {
+		return errors.New("expected 32 hexadecimal digits")
+	}
+	_, err := hex.Decode(u[:], text)
+	return err
+}
+
+// roundTrip property that a created note is returned unchanged
+func roundTrip() Property {
+	return Property{
+		Name: "POST then GET returns the same note",
+		Type: note{},
+		Check: func(ht *Httptester, input any) {
+			expected := input.(note)
+			if len(expected.Tags) == 0 {
+				// empty tags are omitted from the response
+				expected.Tags = nil
+			}
+			ht.Post("/notes", nil)
+			ht.SetRequestBodyJSON(expected)
+			ht.Execute()
+			ht.AssertStatusCode(http.StatusCreated)
+			var created struct {
+				ID int `json:"id"`
+			}
+			ht.AssertStruct(&created, func(interface{}) bool { return true })
+			ht.Get("/notes/" + strconv.Itoa(created.ID))
+			ht.Execute()
+			ht.AssertStructDeepEquals(&note{}, &expected)
+		},
+	}
+}
+
+func TestCheckProperty(t *testing.T) {
+	t.Parallel()
+	t.Run("property holds", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, noteHandler(1000))
+		tester.CheckProperty(roundTrip(), PropertyOptions{Seed: 1})
+	})
+
+	t.Run("schema inputs", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, noteHandler(1000))
+		tester.CheckProperty(Property{
+			Name:   "DELETE is idempotent",
+			Schema: json.RawMessage(`{"type": "object", "properties": {"title": {"type": "string", "minLength": 1}}, "required": ["title"]}`),
+			Check: func(ht *Httptester, input any) {
+				if title := input.(map[string]any)["title"].(string); title == "" {
+					ht.t.Fatalf("Expected a title of at least 1 character")
+				}
+				ht.Post("/notes", nil)
+				ht.SetRequestBodyJSON(input)
+				ht.Execute()
+				ht.AssertStatusCode(http.StatusCreated)
+				ht.Delete("/notes/0")
+				ht.Execute()
+				ht.AssertStatusCode(http.StatusNoContent)
+				ht.Delete("/notes/0")
+				ht.Execute()
+				ht.AssertStatusCode(http.StatusNoContent)
+			},
+		}, PropertyOptions{Runs: 20, Seed: 1})
+	})
+
+	t.Run("failing input is shrunk", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, noteHandler(8))
+
+		defer func() {
+			message, _ := recover().(string)
+			_, input, ok := strings.Cut(message, "Input: ")
+			if !ok {
+				t.Fatalf("Expected the failing input; got %q", message)
+			}
+			input, _, _ = strings.Cut(input, "\n")
+			var shrunk note
+			if err := json.Unmarshal([]byte(input), &shrunk); err != nil {
+				t.Fatal(err)
+			}
+			if utf8.RuneCountInString(shrunk.Title) != 9 || shrunk.Tags != nil || shrunk.Priority != 0 {
+				t.Fatalf("Expected a minimal note with a 9 character title; got %s", input)
+			}
+			if !strings.Contains(message, `ht.NewRequest("POST", "/notes", strings.NewReader(`) ||
+				!strings.Contains(message, `ht.NewRequest("GET", "/notes/`) {
+				t.Fatalf("Expected the requests as builder code; got %q", message)
+			}
+		}()
+		tester.CheckProperty(roundTrip(), PropertyOptions{Seed: 1})
+	})
+	t.Run("schema without valid inputs", func(t *testing.T) {
+		t.Parallel()
+		defer assertFatal(t)
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, noteHandler(10))
+		tester.CheckProperty(Property{
+			Name:   "empty range",
+			Schema: json.RawMessage(`{"type": "integer", "minimum": 0.5, "maximum": 0.7}`),
+			Check:  func(ht *Httptester, input any) {},
+		}, PropertyOptions{Runs: 1})
+	})
+	t.Run("text unmarshalers are generated as strings", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, noteHandler(10))
+		var runs int
+		tester.CheckProperty(Property{
+			Name: "labels decode",
+			Type: struct {
+				Label label `json:"label"`
+			}{},
+			Check: func(ht *Httptester, input any) { runs++ },
+		}, PropertyOptions{Runs: 10, Seed: 1})
+		if runs != 10 {
+			t.Fatalf("Expected Check to run for every input; got %d runs", runs)
+		}
+	})
+
+	t.Run("input that cannot be decoded fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, noteHandler(10))
+
+		defer func() {
+			message, _ := recover().(string)
+			if !strings.Contains(message, "Failure: Error decoding input into struct { ID httptesting.uuidLike") {
+				t.Errorf("Expected a decoding failure; got %q", message)
+			}
+		}()
+		tester.CheckProperty(Property{
+			Name: "ids decode",
+			Type: struct {
+				ID uuidLike `json:"id"`
+			}{},
+			Check: func(ht *Httptester, input any) {},
+		}, PropertyOptions{Runs: 10, Seed: 1})
+	})
+}
@@ -0,0 +1,220 @@
+package httptesting
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BurstOptions options for ExecuteBurst
+type BurstOptions struct {
+	// Requests maximum number of requests to send before giving up on being rate limited
+	Requests int
+
+	// Interval time to wait between requests using the clock of the httptester. Zero sends the requests back to back
+	Interval time.Duration
+}
+
+// BurstResult responses to the requests sent by ExecuteBurst
+type BurstResult struct {
+	ht *Httptester
+	// template request that was sent, used to verify recovery
+	template *http.Request
+	// body of the template request
+	body []byte
+	// requestContext context options of the template request
+	requestContext *requestContext
+
+	// Sent number of requests sent
+	Sent int
+
+	// Allowed number of requests accepted before the first 429 Too Many Requests
+	Allowed int
+
+	// Throttled is true if a request was answered with 429 Too Many Requests
+	Throttled bool
+
+	// Header headers of the first 429 Too Many Requests response, or of the last response if none was throttled
+	Header http.Header
+
+	// Limits value of the RateLimit-Limit header of each accepted response, -1 if it was missing
+	Limits []int
+
+	// Remaining value of the RateLimit-Remaining header of each accepted response, -1 if it was missing
+	Remaining []int
+
+	// ThrottledAt time the first 429 Too Many Requests response was received according to the clock of the httptester
+	ThrottledAt time.Time
+}
+
+// ExecuteBurst sends copies of the current request until one is answered with 429 Too Many Requests or opts.Requests were sent.
+// Each request is executed the same as Execute, so the previous response is the last one sent and can be asserted with the Assert* functions
+func (ht *Httptester) ExecuteBurst(opts BurstOptions) *BurstResult {
+	ht.t.Helper()
+	if opts.Requests <= 0 {
+		ht.t.Fatalf("Expected a positive number of requests; got %d", opts.Requests)
+	}
+	template := ht.getRequest()
+	body, err := readRequestBody(template)
+	if err != nil {
+		ht.t.Fatalf("Error reading request body: %s", err.Error())
+	}
+	result := &BurstResult{ht: ht, template: template, body: body, requestContext: ht.requestContext}
+
+	clock := ht.getClock()
+	for result.Sent < opts.Requests {
+		if result.Sent > 0 && opts.Interval > 0 {
+			clock.Sleep(opts.Interval)
+		}
+		ht.executeCopy(template, body, result.requestContext)
+		result.Sent++
+		result.Header = ht.state.Response.Header.Clone()
+		if ht.state.Response.StatusCode == http.StatusTooManyRequests {
+			result.Throttled = true
+			result.ThrottledAt = clock.Now()
+			break
+		}
+		result.Allowed++
+		result.Limits = append(result.Limits, headerCount(ht.state.Response.Header, "RateLimit-Limit"))
+		result.Remaining = append(result.Remaining, headerCount(ht.state.Response.Header, "RateLimit-Remaining"))
+	}
+	return result
+}
+
+// headerCount helper function to parse a header as a non-negative integer. Returns -1 if it is missing or invalid
+func headerCount(header http.Header, key string) int {
+	n, err := strconv.Atoi(header.Get(key))
+	if err != nil || n < 0 {
+		return -1
+	}
+	return n
+}
+
+// executeCopy helper function to execute a copy of template as the current request
+func (ht *Httptester) executeCopy(template *http.Request, body []byte, rc *requestContext) {
+	ht.t.Helper()
+	req := template.Clone(template.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	ht.state.Request = req
+	ht.requestContext = rc
+	ht.Execute()
+}
+
+// RetryAfter returns the wait requested by the Retry-After header of the throttled response.
+// HTTP dates are resolved against the time the response was received. Returns false if the header is missing or invalid
+func (r *BurstResult) RetryAfter() (time.Duration, bool) {
+	value := strings.TrimSpace(r.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	wait := date.Sub(r.ThrottledAt)
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
+
+// AssertLimit asserts exactly limit requests were accepted before the handler answered with 429 Too Many Requests
+func (r *BurstResult) AssertLimit(limit int) {
+	r.ht.t.Helper()
+	defer r.ht.assertion("AssertLimit")()
+	if !r.Throttled {
+		r.ht.t.Fatalf("Expected to be rate limited after %d requests; %d requests were accepted", limit, r.Allowed)
+	}
+	if r.Allowed != limit {
+		r.ht.t.Fatalf("Expected to be rate limited after %d requests; got %d", limit, r.Allowed)
+	}
+}
+
+// AssertNotThrottled asserts none of the requests were answered with 429 Too Many Requests
+func (r *BurstResult) AssertNotThrottled() {
+	r.ht.t.Helper()
+	defer r.ht.assertion("AssertNotThrottled")()
+	if r.Throttled {
+		r.ht.t.Fatalf("Expected no request to be rate limited; request %d was", r.Sent)
+	}
+}
+
+// AssertRetryAfter asserts the throttled response has a Retry-After header requesting a wait between minWait and maxWait
+func (r *BurstResult) AssertRetryAfter(minWait, maxWait time.Duration) {
+	r.ht.t.Helper()
+	defer r.ht.assertion("AssertRetryAfter")()
+	if !r.Throttled {
+		r.ht.t.Fatalf("Expected a rate limited response; %d requests were accepted", r.Allowed)
+	}
+	wait, ok := r.RetryAfter()
+	if !ok {
+		r.ht.t.Fatalf("Expected a valid Retry-After header; got %q", r.Header.Get("Retry-After"))
+	}
+	if wait < minWait || wait > maxWait {
+		r.ht.t.Fatalf("Expected Retry-After between %s and %s; got %s", minWait, maxWait, wait)
+	}
+}
+
+// AssertRateLimitHeaders asserts every accepted response and the throttled response have RateLimit-Limit equal to limit,
+// that RateLimit-Remaining counts down to 0 and that the throttled response has a RateLimit-Reset
+func (r *BurstResult) AssertRateLimitHeaders(limit int) {
+	r.ht.t.Helper()
+	defer r.ht.assertion("AssertRateLimitHeaders")()
+	if !r.Throttled {
+		r.ht.t.Fatalf("Expected a rate limited response; %d requests were accepted", r.Allowed)
+	}
+	for i, got := range r.Limits {
+		if got != limit {
+			r.ht.t.Fatalf("Expected RateLimit-Limit %d on response %d; got %v", limit, i+1, r.Limits)
+		}
+	}
+	if got := r.Header.Get("RateLimit-Limit"); got != strconv.Itoa(limit) {
+		r.ht.t.Fatalf("Expected RateLimit-Limit %d on the rate limited response; got %q", limit, got)
+	}
+	for i, remaining := range r.Remaining {
+		if remaining < 0 {
+			r.ht.t.Fatalf("Expected RateLimit-Remaining on response %d", i+1)
+		}
+		if i > 0 && remaining >= r.Remaining[i-1] {
+			r.ht.t.Fatalf("Expected RateLimit-Remaining to decrease; got %v", r.Remaining)
+		}
+	}
+	if got := r.Header.Get("RateLimit-Remaining"); got != "0" {
+		r.ht.t.Fatalf("Expected RateLimit-Remaining 0 when rate limited; got %q", got)
+	}
+	if _, err := strconv.Atoi(r.Header.Get("RateLimit-Reset")); err != nil {
+		r.ht.t.Fatalf("Expected a RateLimit-Reset number of seconds; got %q", r.Header.Get("RateLimit-Reset"))
+	}
+}
+
+// AssertRecovery waits for the Retry-After of the throttled response, or RateLimit-Reset if it is missing, using the clock of the httptester
+// and asserts the request is accepted again
+func (r *BurstResult) AssertRecovery() {
+	r.ht.t.Helper()
+	defer r.ht.assertion("AssertRecovery")()
+	if !r.Throttled {
+		r.ht.t.Fatalf("Expected a rate limited response; %d requests were accepted", r.Allowed)
+	}
+	wait, ok := r.RetryAfter()
+	if !ok {
+		seconds, err := strconv.Atoi(r.Header.Get("RateLimit-Reset"))
+		if err != nil {
+			r.ht.t.Fatalf("Expected a Retry-After or RateLimit-Reset header to wait for")
+		}
+		wait = time.Duration(seconds) * time.Second
+	}
+	clock := r.ht.getClock()
+	if elapsed := clock.Now().Sub(r.ThrottledAt); elapsed < wait {
+		clock.Sleep(wait - elapsed)
+	}
+	r.ht.executeCopy(r.template, r.body, r.requestContext)
+	if code := r.ht.state.Response.StatusCode; code == http.StatusTooManyRequests {
+		r.ht.t.Fatalf("Expected request to be accepted after waiting %s; still rate limited", wait)
+	}
+}
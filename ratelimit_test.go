@@ -0,0 +1,155 @@
+package httptesting
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// limitHandler allows limit requests per window of the clock
+func limitHandler(clock Clock, limit int, window time.Duration, retryAfter bool) http.Handler {
+	var mu sync.Mutex
+	var windowStart time.Time
+	var count int
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		now := clock.Now()
+		if now.Sub(windowStart) >= window {
+			windowStart, count = now, 0
+		}
+		reset := windowStart.Add(window).Sub(now)
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(limit))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(int(reset.Seconds())))
+		if count >= limit {
+			w.Header().Set("RateLimit-Remaining", "0")
+			if retryAfter {
+				w.Header().Set("Retry-After", strconv.Itoa(int(reset.Seconds())))
+			}
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		count++
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(limit-count))
+		_, err := w.Write([]byte("Ok"))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+}
+
+func TestExecuteBurst(t *testing.T) {
+	t.Parallel()
+	t.Run("finds the limit and recovers", func(t *testing.T) {
+		t.Parallel()
+		clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		tester := New(t, limitHandler(clock, 5, time.Minute, true))
+		tester.SetClock(clock)
+		tester.Get("/")
+		result := tester.ExecuteBurst(BurstOptions{Requests: 20, Interval: time.Second})
+		result.AssertLimit(5)
+		result.AssertRetryAfter(50*time.Second, time.Minute)
+		result.AssertRateLimitHeaders(5)
+		tester.AssertStatusCode(http.StatusTooManyRequests)
+		result.AssertRecovery()
+		tester.AssertStatusCode(http.StatusOK)
+		tester.AssertBody([]byte("Ok"))
+	})
+
+	t.Run("recovers using RateLimit-Reset", func(t *testing.T) {
+		t.Parallel()
+		clock := NewFakeClock(time.Time{})
+		tester := New(t, limitHandler(clock, 2, 10*time.Second, false))
+		tester.SetClock(clock)
+		tester.Get("/")
+		result := tester.ExecuteBurst(BurstOptions{Requests: 3})
+		result.AssertLimit(2)
+		result.AssertRecovery()
+	})
+
+	t.Run("requests under the limit", func(t *testing.T) {
+		t.Parallel()
+		clock := NewFakeClock(time.Time{})
+		tester := New(t, limitHandler(clock, 5, time.Second, true))
+		tester.SetClock(clock)
+		tester.Get("/")
+		result := tester.ExecuteBurst(BurstOptions{Requests: 10, Interval: time.Second})
+		result.AssertNotThrottled()
+		if result.Sent != 10 {
+			t.Fatalf("Expected 10 requests to be sent; got %d", result.Sent)
+		}
+	})
+
+	t.Run("retry after http date", func(t *testing.T) {
+		t.Parallel()
+		throttledAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		result := &BurstResult{
+			Header:      http.Header{"Retry-After": []string{throttledAt.Add(30 * time.Second).Format(http.TimeFormat)}},
+			ThrottledAt: throttledAt,
+		}
+		if wait, ok := result.RetryAfter(); !ok || wait != 30*time.Second {
+			t.Fatalf("Expected a Retry-After of 30s; got %s", wait)
+		}
+	})
+
+	t.Run("wrong limit fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		clock := NewFakeClock(time.Time{})
+		tester := New(&mockT, limitHandler(clock, 3, time.Minute, true))
+		tester.SetClock(clock)
+		tester.Get("/")
+		result := tester.ExecuteBurst(BurstOptions{Requests: 10})
+
+		defer assertFatal(t)
+		result.AssertLimit(5)
+	})
+	t.Run("accepted response without RateLimit-Limit fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		clock := NewFakeClock(time.Time{})
+		limited := limitHandler(clock, 3, time.Minute, true)
+		var requests int
+		tester := New(&mockT, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 2 {
+				w = omitHeaderWriter{ResponseWriter: w, key: "RateLimit-Limit"}
+			}
+			limited.ServeHTTP(w, r)
+		}))
+		tester.SetClock(clock)
+		tester.Get("/")
+		result := tester.ExecuteBurst(BurstOptions{Requests: 10})
+		if result.Limits[1] != -1 {
+			t.Fatalf("Expected the missing RateLimit-Limit to be recorded as -1; got %v", result.Limits)
+		}
+
+		defer func() {
+			message, _ := recover().(string)
+			if message != "Expected RateLimit-Limit 3 on response 2; got [3 -1 3]" {
+				t.Errorf("Unexpected failure %q", message)
+			}
+		}()
+		result.AssertRateLimitHeaders(3)
+	})
+}
+
+// omitHeaderWriter removes the header key before the response is written
+type omitHeaderWriter struct {
+	http.ResponseWriter
+	key string
+}
+
+func (w omitHeaderWriter) WriteHeader(statusCode int) {
+	w.Header().Del(w.key)
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w omitHeaderWriter) Write(b []byte) (int, error) {
+	w.Header().Del(w.key)
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,310 @@
+package httptesting
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// Reporter records every executed request and assertion made by the httptesters it is set on
+// and writes them as JUnit XML or JSON reports. A Reporter is safe for concurrent use and is
+// usually shared between all tests in a package and written in TestMain
+type Reporter struct {
+	mu     sync.Mutex
+	name   string
+	suites []*reportSuite
+}
+
+// reportSuite exchanges made by a single httptester
+type reportSuite struct {
+	reporter *Reporter
+	name     string
+	start    time.Time
+	cases    []*reportCase
+}
+
+// reportCase an exchange and the assertions made on its response.
+// exchange is nil for assertions made before any request was executed
+type reportCase struct {
+	exchange   *Exchange
+	assertions []reportAssertion
+}
+
+// reportAssertion outcome of a single Assert* call
+type reportAssertion struct {
+	name    string
+	message string
+}
+
+// reportingT TestingT that records failure messages for the Reporter
+type reportingT struct {
+	util.TestingT
+	ht *Httptester
+}
+
+// Fatalf records the failure message and fails the test
+func (t *reportingT) Fatalf(format string, args ...any) {
+	t.TestingT.Helper()
+	t.ht.failure = fmt.Sprintf(format, args...)
+	if t.ht.failure == "" {
+		t.ht.failure = "assertion failed"
+	}
+	t.TestingT.Fatalf(format, args...)
+}
+
+// NewReporter returns a new Reporter. name is used as the name of the JUnit testsuites element and JSON report
+func NewReporter(name string) *Reporter {
+	return &Reporter{name: name}
+}
+
+// SetReporter records every subsequent request and assertion made by the httptester with r.
+// Each httptester is reported as a test suite named after the test
+func (ht *Httptester) SetReporter(r *Reporter) {
+	r.mu.Lock()
+	name := ht.t.Name()
+	if name == "" {
+		name = fmt.Sprintf("httptester %d", len(r.suites)+1)
+	}
+	ht.suite = &reportSuite{reporter: r, name: name, start: time.Now()}
+	r.suites = append(r.suites, ht.suite)
+	r.mu.Unlock()
+	if _, ok := ht.t.(*reportingT); !ok {
+		ht.t = &reportingT{TestingT: ht.t, ht: ht}
+	}
+}
+
+// assertion helper function to record the outcome of an assertion with the Reporter.
+// Must be deferred at the start of every Assert* function: defer ht.assertion("AssertStatus")()
+func (ht *Httptester) assertion(name string) func() {
+	if ht.suite == nil {
+		return func() {}
+	}
+	ht.failure = ""
+	executed := ht.requestExecuted
+	return func() {
+		ht.suite.addAssertion(executed, name, ht.failure)
+	}
+}
+
+// addExchange records an executed request
+func (s *reportSuite) addExchange(e *Exchange) {
+	s.reporter.mu.Lock()
+	defer s.reporter.mu.Unlock()
+	s.cases = append(s.cases, &reportCase{exchange: e})
+}
+
+// addAssertion records an assertion on the last exchange, or on its own case if no request was executed
+func (s *reportSuite) addAssertion(executed bool, name, message string) {
+	s.reporter.mu.Lock()
+	defer s.reporter.mu.Unlock()
+	if !executed || len(s.cases) == 0 {
+		s.cases = append(s.cases, &reportCase{})
+	}
+	c := s.cases[len(s.cases)-1]
+	c.assertions = append(c.assertions, reportAssertion{name: name, message: message})
+}
+
+// name returns the name of the test case
+func (c *reportCase) name() string {
+	if c.exchange == nil {
+		return "no request executed"
+	}
+	return c.exchange.String()
+}
+
+// duration returns the time the handler took to serve the request
+func (c *reportCase) duration() time.Duration {
+	if c.exchange == nil {
+		return 0
+	}
+	return c.exchange.Duration
+}
+
+// failures returns the failed assertions of the test case
+func (c *reportCase) failures() []reportAssertion {
+	var failed []reportAssertion
+	for _, a := range c.assertions {
+		if a.message != "" {
+			failed = append(failed, a)
+		}
+	}
+	return failed
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// seconds formats a duration as seconds for JUnit time attributes
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.6f", d.Seconds())
+}
+
+// WriteJUnit writes the report as JUnit XML. Each httptester is a testsuite and each executed request is a testcase
+// that fails if any assertion made on its response failed
+func (r *Reporter) WriteJUnit(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := junitTestSuites{Name: r.name}
+	var total time.Duration
+	for _, s := range r.suites {
+		suite := junitTestSuite{Name: s.name, Timestamp: s.start.Format(time.RFC3339)}
+		var suiteTime time.Duration
+		for _, c := range s.cases {
+			testCase := junitTestCase{Name: c.name(), ClassName: s.name, Time: seconds(c.duration())}
+			var out strings.Builder
+			for _, a := range c.assertions {
+				status := "PASS"
+				if a.message != "" {
+					status = "FAIL"
+				}
+				fmt.Fprintf(&out, "%s %s\n", status, a.name)
+			}
+			testCase.SystemOut = out.String()
+			if failed := c.failures(); len(failed) > 0 {
+				var messages []string
+				for _, a := range failed {
+					messages = append(messages, fmt.Sprintf("%s: %s", a.name, a.message))
+				}
+				testCase.Failure = &junitFailure{
+					Message: failed[0].message,
+					Type:    failed[0].name,
+					Text:    strings.Join(messages, "\n"),
+				}
+				suite.Failures++
+			}
+			suite.Tests++
+			suiteTime += c.duration()
+			suite.Cases = append(suite.Cases, testCase)
+		}
+		suite.Time = seconds(suiteTime)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		total += suiteTime
+		report.Suites = append(report.Suites, suite)
+	}
+	report.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type jsonReport struct {
+	Name     string            `json:"name"`
+	Tests    int               `json:"tests"`
+	Failures int               `json:"failures"`
+	Suites   []jsonReportSuite `json:"suites"`
+}
+
+type jsonReportSuite struct {
+	Name      string               `json:"name"`
+	Tests     int                  `json:"tests"`
+	Failures  int                  `json:"failures"`
+	Exchanges []jsonReportExchange `json:"exchanges"`
+}
+
+type jsonReportExchange struct {
+	Method            string                `json:"method,omitempty"`
+	URL               string                `json:"url,omitempty"`
+	StatusCode        int                   `json:"statusCode,omitempty"`
+	RequestBodyBytes  int                   `json:"requestBodyBytes"`
+	ResponseBodyBytes int                   `json:"responseBodyBytes"`
+	ContentType       string                `json:"contentType,omitempty"`
+	DurationMs        float64               `json:"durationMs"`
+	Passed            bool                  `json:"passed"`
+	Assertions        []jsonReportAssertion `json:"assertions"`
+}
+
+type jsonReportAssertion struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// WriteJSON writes the report as JSON with a summary of every executed request, its duration and the outcome of each assertion
+func (r *Reporter) WriteJSON(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := jsonReport{Name: r.name, Suites: []jsonReportSuite{}}
+	for _, s := range r.suites {
+		suite := jsonReportSuite{Name: s.name, Exchanges: []jsonReportExchange{}}
+		for _, c := range s.cases {
+			exchange := jsonReportExchange{
+				DurationMs: float64(c.duration()) / float64(time.Millisecond),
+				Passed:     len(c.failures()) == 0,
+				Assertions: []jsonReportAssertion{},
+			}
+			if e := c.exchange; e != nil {
+				exchange.Method = e.Method
+				exchange.URL = e.URL
+				exchange.StatusCode = e.StatusCode
+				exchange.RequestBodyBytes = len(e.RequestBody)
+				exchange.ResponseBodyBytes = len(e.ResponseBody)
+				exchange.ContentType = e.ResponseHeader.Get("Content-Type")
+			}
+			for _, a := range c.assertions {
+				exchange.Assertions = append(exchange.Assertions, jsonReportAssertion{
+					Name:    a.name,
+					Passed:  a.message == "",
+					Message: a.message,
+				})
+			}
+			suite.Tests++
+			if !exchange.Passed {
+				suite.Failures++
+			}
+			suite.Exchanges = append(suite.Exchanges, exchange)
+		}
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
@@ -0,0 +1,113 @@
+package httptesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+func assertFatalRecovered(t *testing.T, f func()) {
+	t.Helper()
+	defer assertFatal(t)
+	f()
+}
+
+func TestReporter(t *testing.T) {
+	t.Parallel()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, err := w.Write([]byte("Ok"))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+
+	newReport := func(t *testing.T) *Reporter {
+		reporter := NewReporter("api")
+		tester := New(t, handler)
+		tester.SetReporter(reporter)
+		tester.Post("/todo", strings.NewReader("body"))
+		tester.Execute()
+		tester.AssertStatusCode(http.StatusOK)
+		tester.AssertBody([]byte("Ok"))
+
+		mockT := util.MockTestingT{}
+		failing := New(&mockT, handler)
+		failing.SetReporter(reporter)
+		failing.Get("/todo")
+		failing.Execute()
+		assertFatalRecovered(t, func() { failing.AssertStatusCode(http.StatusCreated) })
+		return reporter
+	}
+
+	t.Run("writes junit xml", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		if err := newReport(t).WriteJUnit(&buf); err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		var report junitTestSuites
+		if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+			t.Fatalf("Error decoding junit xml: %s", err.Error())
+		}
+		if report.Tests != 2 || report.Failures != 1 || len(report.Suites) != 2 {
+			t.Fatalf("Expected 2 tests and 1 failure in 2 suites; got %+v", report)
+		}
+		if report.Suites[0].Name != t.Name() {
+			t.Errorf("Expected suite to be named %q; got %q", t.Name(), report.Suites[0].Name)
+		}
+		testCase := report.Suites[1].Cases[0]
+		if testCase.Name != "GET /todo -> 200" || testCase.Failure == nil {
+			t.Fatalf("Expected failed test case for GET /todo; got %+v", testCase)
+		}
+		if testCase.Failure.Type != "AssertStatusCode" || !strings.Contains(testCase.Failure.Message, "201") {
+			t.Errorf("Expected AssertStatusCode failure message; got %+v", testCase.Failure)
+		}
+	})
+
+	t.Run("writes json", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		if err := newReport(t).WriteJSON(&buf); err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		var report jsonReport
+		if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+			t.Fatalf("Error decoding json report: %s", err.Error())
+		}
+		exchange := report.Suites[0].Exchanges[0]
+		if exchange.Method != http.MethodPost || exchange.RequestBodyBytes != 4 || exchange.ContentType != "text/plain" {
+			t.Errorf("Expected summary of POST /todo; got %+v", exchange)
+		}
+		if !exchange.Passed || len(exchange.Assertions) != 2 {
+			t.Errorf("Expected 2 passing assertions; got %+v", exchange.Assertions)
+		}
+		failed := report.Suites[1].Exchanges[0]
+		if failed.Passed || failed.Assertions[0].Message == "" {
+			t.Errorf("Expected failed assertion with message; got %+v", failed)
+		}
+	})
+
+	t.Run("assertion before execute is reported", func(t *testing.T) {
+		t.Parallel()
+		reporter := NewReporter("api")
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, handler)
+		tester.SetReporter(reporter)
+		tester.Get("/todo")
+		assertFatalRecovered(t, func() { tester.AssertStatusCode(http.StatusOK) })
+
+		var buf bytes.Buffer
+		if err := reporter.WriteJSON(&buf); err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if !strings.Contains(buf.String(), "was not executed") {
+			t.Errorf("Expected report to contain failure message; got %s", buf.String())
+		}
+	})
+}
@@ -0,0 +1,264 @@
+package httptesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// Scenario a named sequence of requests executed in order against the same httptester.
+// Cookies and saved values are chained between steps the same as chained calls to Execute
+type Scenario struct {
+	// Name name of the scenario used in output and for filtering
+	Name string `json:"name"`
+
+	// Tags optional labels used for filtering scenarios
+	Tags []string `json:"tags,omitempty"`
+
+	// Steps requests executed in order
+	Steps []Step `json:"steps"`
+}
+
+// Step a single request in a Scenario and the assertions made on its response.
+// Path, Headers, Body, JSON and Expect may reference variables with ${NAME}
+type Step struct {
+	// Name optional name of the step used in failure messages
+	Name string `json:"name,omitempty"`
+
+	// Method http method of the request. Defaults to GET
+	Method string `json:"method,omitempty"`
+
+	// Path path and query of the request
+	Path string `json:"path"`
+
+	// Headers headers added to the request
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body raw request body
+	Body string `json:"body,omitempty"`
+
+	// JSON request body encoded as JSON. Sets Content-Type to application/json unless set in Headers
+	JSON json.RawMessage `json:"json,omitempty"`
+
+	// Expect assertions made on the response
+	Expect Expect `json:"expect"`
+
+	// Save maps value names to dotted paths in the JSON response body, e.g. "items.0.id".
+	// Saved values are stored in State.Values and can be referenced by later steps
+	Save map[string]string `json:"save,omitempty"`
+}
+
+// Expect assertions made on the response of a Step. Zero values are not asserted
+type Expect struct {
+	// Status expected status code
+	Status int `json:"status,omitempty"`
+
+	// Headers expected header values
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body expected raw response body
+	Body *string `json:"body,omitempty"`
+
+	// JSON expected response body, compared semantically with the decoded response body
+	JSON json.RawMessage `json:"json,omitempty"`
+}
+
+// variablePattern matches ${NAME} references in scenario steps
+var variablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_.\-]*)\}`)
+
+// LoadScenarioFile reads the scenarios in a JSON file.
+// The file may contain a single scenario object or an array of scenarios
+func LoadScenarioFile(path string) ([]Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseScenarios(data)
+}
+
+// ParseScenarios decodes a single scenario object or an array of scenarios from JSON
+func ParseScenarios(data []byte) ([]Scenario, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var scenarios []Scenario
+		if err := json.Unmarshal(data, &scenarios); err != nil {
+			return nil, err
+		}
+		return scenarios, nil
+	}
+	var scenario Scenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return nil, err
+	}
+	return []Scenario{scenario}, nil
+}
+
+// HasAnyTag returns true if the scenario has at least one of the tags
+func (s Scenario) HasAnyTag(tags ...string) bool {
+	for _, want := range tags {
+		for _, tag := range s.Tags {
+			if tag == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stepT TestingT that prefixes failure messages with the scenario step that failed
+type stepT struct {
+	util.TestingT
+	prefix string
+}
+
+// Fatalf prefixes the failure message with the current step
+func (t *stepT) Fatalf(format string, args ...any) {
+	t.TestingT.Helper()
+	t.TestingT.Fatalf("%s: %s", t.prefix, fmt.Sprintf(format, args...))
+}
+
+// RunScenario executes every step of the scenario in order and asserts the expected responses.
+// Variables are resolved from values saved by previous steps first and then from vars
+func (ht *Httptester) RunScenario(s Scenario, vars map[string]string) {
+	ht.t.Helper()
+	t := ht.t
+	defer func() { ht.t = t }()
+
+	for i, step := range s.Steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step %d", i+1)
+		}
+		ht.t = &stepT{TestingT: t, prefix: fmt.Sprintf("Scenario %q %s", s.Name, name)}
+		ht.runStep(step, vars)
+	}
+}
+
+// runStep executes a single scenario step
+func (ht *Httptester) runStep(step Step, vars map[string]string) {
+	ht.t.Helper()
+	method := step.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	if len(step.JSON) > 0 {
+		body = strings.NewReader(ht.expand(string(step.JSON), vars))
+	} else if step.Body != "" {
+		body = strings.NewReader(ht.expand(step.Body, vars))
+	}
+	ht.NewRequest(strings.ToUpper(method), ht.expand(step.Path, vars), body)
+	if len(step.JSON) > 0 {
+		ht.AddHeader("Content-Type", "application/json")
+	}
+	for _, key := range sortedKeys(step.Headers) {
+		ht.AddHeader(key, ht.expand(step.Headers[key], vars))
+	}
+	ht.Execute()
+
+	if step.Expect.Status != 0 {
+		ht.AssertStatusCode(step.Expect.Status)
+	}
+	for _, key := range sortedKeys(step.Expect.Headers) {
+		ht.AssertHeader(key, ht.expand(step.Expect.Headers[key], vars))
+	}
+	if step.Expect.Body != nil {
+		ht.AssertBody([]byte(ht.expand(*step.Expect.Body, vars)))
+	}
+	if len(step.Expect.JSON) > 0 {
+		ht.assertJSONEquals([]byte(ht.expand(string(step.Expect.JSON), vars)))
+	}
+	if len(step.Save) > 0 {
+		var decoded any
+		decoder := json.NewDecoder(bytes.NewReader(ht.responseBody()))
+		decoder.UseNumber()
+		if err := decoder.Decode(&decoded); err != nil {
+			ht.t.Fatalf("Error parsing response json: %s", err.Error())
+		}
+		for _, key := range sortedKeys(step.Save) {
+			path := step.Save[key]
+			value, ok := lookupPath(decoded, path)
+			if !ok {
+				ht.t.Fatalf("Could not find %q in response body", path)
+			}
+			ht.SetValue(key, value)
+		}
+	}
+}
+
+// assertJSONEquals asserts the response body is semantically equal to the expected JSON document
+func (ht *Httptester) assertJSONEquals(expected []byte) {
+	ht.t.Helper()
+	defer ht.assertion("AssertJSON")()
+	ht.assertRequestExecuted()
+	var want, got any
+	if err := json.Unmarshal(expected, &want); err != nil {
+		ht.t.Fatalf("Error parsing expected json: %s", err.Error())
+	}
+	body := ht.responseBody()
+	if err := json.Unmarshal(body, &got); err != nil {
+		ht.t.Fatalf("Error parsing response json: %s", err.Error())
+	}
+	if !reflect.DeepEqual(want, got) {
+		ht.t.Fatalf("Expected JSON %s; got %s", expected, body)
+	}
+}
+
+// expand replaces ${NAME} references with saved values or vars
+func (ht *Httptester) expand(s string, vars map[string]string) string {
+	ht.t.Helper()
+	return variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		ht.t.Helper()
+		name := variablePattern.FindStringSubmatch(match)[1]
+		if value, ok := ht.state.Values[name]; ok {
+			return fmt.Sprint(value)
+		}
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		ht.t.Fatalf("Undefined variable %q", name)
+		return match
+	})
+}
+
+// lookupPath finds the value at a dotted path in a decoded JSON document.
+// Numeric segments index into arrays
+func lookupPath(v any, path string) (any, bool) {
+	if path == "" {
+		return v, true
+	}
+	return lookupSegments(v, strings.Split(path, "."))
+}
+
+// lookupSegments finds the value at a path of object keys and array indices in a decoded JSON document
+func lookupSegments(v any, segments []string) (any, bool) {
+	for _, segment := range segments {
+		switch node := v.(type) {
+		case map[string]any:
+			value, ok := node[segment]
+			if !ok {
+				return nil, false
+			}
+			v = value
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
@@ -0,0 +1,195 @@
+package httptesting
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+func todoHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/todo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, err := w.Write([]byte(`{"id": 42, "name": "Get Groceries"}`))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	mux.HandleFunc("/todo/42", func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte("Get Groceries"))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	return mux
+}
+
+const todoScenario = `{
+	"name": "create todo",
+	"tags": ["smoke"],
+	"steps": [
+		{
+			"method": "POST",
+			"path": "/todo",
+			"headers": {"Authorization": "Bearer ${TOKEN}"},
+			"json": {"name": "Get Groceries"},
+			"expect": {
+				"status": 201,
+				"headers": {"Content-Type": "application/json"},
+				"json": {"name": "Get Groceries", "id": 42}
+			},
+			"save": {"id": "id"}
+		},
+		{
+			"name": "get todo",
+			"path": "/todo/${id}",
+			"expect": {"status": 200, "body": "Get Groceries"}
+		}
+	]
+}`
+
+func TestParseScenarios(t *testing.T) {
+	t.Parallel()
+	t.Run("parses a single scenario", func(t *testing.T) {
+		t.Parallel()
+		scenarios, err := ParseScenarios([]byte(todoScenario))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if len(scenarios) != 1 || len(scenarios[0].Steps) != 2 {
+			t.Fatalf("Expected 1 scenario with 2 steps; got %v", scenarios)
+		}
+		if !scenarios[0].HasAnyTag("slow", "smoke") {
+			t.Errorf("Expected scenario to have tag smoke")
+		}
+	})
+
+	t.Run("parses an array of scenarios", func(t *testing.T) {
+		t.Parallel()
+		scenarios, err := ParseScenarios([]byte(fmt.Sprintf(" [%s, %s]", todoScenario, todoScenario)))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if len(scenarios) != 2 {
+			t.Errorf("Expected 2 scenarios; got %d", len(scenarios))
+		}
+	})
+
+	t.Run("loads scenario file", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "todo.json")
+		if err := os.WriteFile(path, []byte(todoScenario), 0o600); err != nil {
+			t.Fatalf("Error writing scenario file: %s", err.Error())
+		}
+		scenarios, err := LoadScenarioFile(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if scenarios[0].Name != "create todo" {
+			t.Errorf("Expected name %q; got %q", "create todo", scenarios[0].Name)
+		}
+	})
+
+	t.Run("invalid json returns error", func(t *testing.T) {
+		t.Parallel()
+		if _, err := ParseScenarios([]byte(`{"name": `)); err == nil {
+			t.Errorf("Expected error parsing invalid json")
+		}
+	})
+}
+
+func TestRunScenario(t *testing.T) {
+	t.Parallel()
+	t.Run("scenario passes", func(t *testing.T) {
+		t.Parallel()
+		scenarios, err := ParseScenarios([]byte(todoScenario))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		tester := New(t, todoHandler())
+		tester.RunScenario(scenarios[0], map[string]string{"TOKEN": "secret"})
+		if fmt.Sprint(tester.state.Values["id"]) != "42" {
+			t.Errorf("Expected saved id to be 42; got %v", tester.state.Values["id"])
+		}
+	})
+
+	t.Run("failed step fails test", func(t *testing.T) {
+		t.Parallel()
+		scenarios, err := ParseScenarios([]byte(todoScenario))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, todoHandler())
+		defer assertFatal(t)
+		tester.RunScenario(scenarios[0], map[string]string{"TOKEN": "wrong"})
+	})
+
+	t.Run("undefined variable fails test", func(t *testing.T) {
+		t.Parallel()
+		scenarios, err := ParseScenarios([]byte(todoScenario))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, todoHandler())
+		defer assertFatal(t)
+		tester.RunScenario(scenarios[0], nil)
+	})
+
+	t.Run("json mismatch fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, todoHandler())
+		defer assertFatal(t)
+		tester.RunScenario(Scenario{
+			Name: "mismatch",
+			Steps: []Step{{
+				Method:  http.MethodPost,
+				Path:    "/todo",
+				Headers: map[string]string{"Authorization": "Bearer secret"},
+				Expect:  Expect{JSON: json.RawMessage(`{"id": 43}`)},
+			}},
+		}, nil)
+	})
+	t.Run("first failing header in key order is reported", func(t *testing.T) {
+		t.Parallel()
+		for i := 0; i < 20; i++ {
+			func() {
+				mockT := util.MockTestingT{}
+				tester := New(&mockT, todoHandler())
+				defer func() {
+					message, _ := recover().(string)
+					if !strings.Contains(message, "header Cache-Control") {
+						t.Fatalf("Expected the Cache-Control header to be reported first; got %q", message)
+					}
+				}()
+				tester.RunScenario(Scenario{
+					Name: "headers",
+					Steps: []Step{{
+						Method:  http.MethodPost,
+						Path:    "/todo",
+						Headers: map[string]string{"Authorization": "Bearer secret"},
+						Expect: Expect{Headers: map[string]string{
+							"X-Request-Id":  "1",
+							"Content-Type":  "text/plain",
+							"Cache-Control": "no-store",
+							"Etag":          "v1",
+						}},
+					}},
+				}, nil)
+			}()
+		}
+	})
+}
@@ -0,0 +1,501 @@
+package httptesting
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// jsonSchema subset of JSON Schema used to generate random valid values
+type jsonSchema struct {
+	Type                 string                 `json:"type"`
+	Format               string                 `json:"format"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *jsonSchema            `json:"-"`
+	Items                *jsonSchema            `json:"items"`
+	Enum                 []any                  `json:"enum"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+}
+
+// parseSchema parses a JSON Schema. Supports type, format, properties, required, items, enum, minimum, maximum,
+// minLength, maxLength, minItems and maxItems
+func parseSchema(data []byte) (*jsonSchema, error) {
+	var s jsonSchema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// timeType reflect.Type of time.Time, generated as an RFC 3339 string
+var timeType = reflect.TypeOf(time.Time{})
+
+// textUnmarshalerType and jsonUnmarshalerType reflect.Type of the interfaces of types decoded from their own encoding, generated as strings
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// schemaForType returns the schema of the JSON encoding of values of t.
+// Returns an error for recursive types, which have no finite schema to generate values from
+func schemaForType(t reflect.Type) (*jsonSchema, error) {
+	return typeSchema(t, make(map[reflect.Type]bool))
+}
+
+// typeSchema helper function for schemaForType. visiting holds the struct types the schema of t is nested in
+func typeSchema(t reflect.Type, visiting map[reflect.Type]bool) (*jsonSchema, error) {
+	if t.Kind() == reflect.Pointer {
+		return typeSchema(t.Elem(), visiting)
+	}
+	if t == timeType {
+		return &jsonSchema{Type: "string", Format: "date-time"}, nil
+	}
+	if p := reflect.PointerTo(t); p.Implements(textUnmarshalerType) || p.Implements(jsonUnmarshalerType) {
+		return &jsonSchema{Type: "string"}, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &jsonSchema{Type: "string"}, nil
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := float64(t.Bits() - 1)
+		minimum, maximum := -math.Pow(2, bits), math.Pow(2, bits)-1
+		return &jsonSchema{Type: "integer", Minimum: &minimum, Maximum: &maximum}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum, maximum := 0.0, math.Pow(2, float64(t.Bits()))-1
+		return &jsonSchema{Type: "integer", Minimum: &minimum, Maximum: &maximum}, nil
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}, nil
+	case reflect.Interface:
+		return &jsonSchema{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &jsonSchema{Type: "string", Format: "byte"}, nil
+		}
+		items, err := typeSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		s := &jsonSchema{Type: "array", Items: items}
+		if t.Kind() == reflect.Array {
+			n := t.Len()
+			s.MinItems, s.MaxItems = &n, &n
+		}
+		return s, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		values, err := typeSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonSchema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		if visiting[t] {
+			return nil, fmt.Errorf("recursive type %s", t)
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		s := &jsonSchema{Type: "object", Properties: make(map[string]*jsonSchema)}
+		if err := addStructFields(s, t, visiting); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// addStructFields helper function to add the exported fields of a struct to an object schema, following encoding/json field names
+func addStructFields(s *jsonSchema, t reflect.Type, visiting map[reflect.Type]bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			if err := addStructFields(s, field.Type, visiting); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property, err := typeSchema(field.Type, visiting)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		s.Properties[name] = property
+		if !strings.Contains(options, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return nil
+}
+
+// stringAlphabet characters used to generate strings
+var stringAlphabet = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 -_.éü✓")
+
+// check returns an error if no value can be generated for the schema or one of its subschemas.
+// path is the JSONPath of the values of the schema used in the error
+func (s *jsonSchema) check(path string) error {
+	if len(s.Enum) > 0 {
+		return nil
+	}
+	if s.Minimum != nil && s.Maximum != nil && *s.Minimum > *s.Maximum {
+		return fmt.Errorf("%s: minimum %v is greater than maximum %v", path, *s.Minimum, *s.Maximum)
+	}
+	if s.Type == "integer" && s.Minimum != nil && s.Maximum != nil && math.Ceil(*s.Minimum) > math.Floor(*s.Maximum) {
+		return fmt.Errorf("%s: no integer between minimum %v and maximum %v", path, *s.Minimum, *s.Maximum)
+	}
+	if err := checkCounts(path, "Length", s.MinLength, s.MaxLength); err != nil {
+		return err
+	}
+	if err := checkCounts(path, "Items", s.MinItems, s.MaxItems); err != nil {
+		return err
+	}
+	if s.Items != nil {
+		if err := s.Items.check(path + "[*]"); err != nil {
+			return err
+		}
+	}
+	if s.AdditionalProperties != nil {
+		if err := s.AdditionalProperties.check(path + ".*"); err != nil {
+			return err
+		}
+	}
+	for _, name := range sortedKeys(s.Properties) {
+		if err := s.Properties[name].check(path + "." + name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkCounts returns an error if the minLength and maxLength or minItems and maxItems of a schema are negative or out of order
+func checkCounts(path, name string, minimum, maximum *int) error {
+	if (minimum != nil && *minimum < 0) || (maximum != nil && *maximum < 0) {
+		return fmt.Errorf("%s: negative min%s or max%s", path, name, name)
+	}
+	if minimum != nil && maximum != nil && *minimum > *maximum {
+		return fmt.Errorf("%s: min%s %d is greater than max%s %d", path, name, *minimum, name, *maximum)
+	}
+	return nil
+}
+
+// generate returns a random value matching the schema. size bounds the length of strings and arrays and the magnitude of numbers
+func (s *jsonSchema) generate(r *rand.Rand, size int) any {
+	if len(s.Enum) > 0 {
+		return s.Enum[r.Intn(len(s.Enum))]
+	}
+	switch s.Type {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return time.Unix(r.Int63n(4102444800), 0).UTC().Format(time.RFC3339)
+		case "byte":
+			b := make([]byte, r.Intn(size+1))
+			r.Read(b)
+			return base64.StdEncoding.EncodeToString(b)
+		}
+		minLength, maxLength := intRange(s.MinLength, s.MaxLength, size)
+		runes := make([]rune, minLength+r.Intn(maxLength-minLength+1))
+		for i := range runes {
+			runes[i] = stringAlphabet[r.Intn(len(stringAlphabet))]
+		}
+		return string(runes)
+	case "integer":
+		minimum, maximum := s.numberRange(size)
+		minimum, maximum = math.Ceil(minimum), math.Floor(maximum)
+		return minimum + float64(r.Int63n(int64(maximum-minimum)+1))
+	case "number":
+		minimum, maximum := s.numberRange(size)
+		return math.Round((minimum+r.Float64()*(maximum-minimum))*100) / 100
+	case "boolean":
+		return r.Intn(2) == 1
+	case "null":
+		return nil
+	case "array":
+		items := s.Items
+		if items == nil {
+			items = &jsonSchema{}
+		}
+		minItems, maxItems := intRange(s.MinItems, s.MaxItems, size)
+		values := make([]any, minItems+r.Intn(maxItems-minItems+1))
+		for i := range values {
+			values[i] = items.generate(r, size)
+		}
+		return values
+	case "object":
+		object := make(map[string]any)
+		for _, name := range sortedKeys(s.Properties) {
+			if s.required(name) || r.Intn(2) == 1 {
+				object[name] = s.Properties[name].generate(r, size)
+			}
+		}
+		if s.AdditionalProperties != nil {
+			for i := r.Intn(size/4 + 1); i > 0; i-- {
+				object[fmt.Sprintf("key%d", r.Intn(size*4+1))] = s.AdditionalProperties.generate(r, size)
+			}
+		}
+		return object
+	}
+	types := []string{"string", "integer", "boolean", "null"}
+	return (&jsonSchema{Type: types[r.Intn(len(types))]}).generate(r, size)
+}
+
+// shrink returns smaller values than v that still match the schema, simplest first
+func (s *jsonSchema) shrink(v any) []any {
+	if len(s.Enum) > 0 {
+		var candidates []any
+		for _, value := range s.Enum {
+			if reflect.DeepEqual(value, v) {
+				break
+			}
+			candidates = append(candidates, value)
+		}
+		return candidates
+	}
+	switch v := v.(type) {
+	case string:
+		if s.Format != "" {
+			return nil
+		}
+		minLength, _ := intRange(s.MinLength, nil, 0)
+		runes := []rune(v)
+		var candidates []any
+		for _, n := range []int{minLength, len(runes) / 2, len(runes) - 1} {
+			if n >= minLength && n < len(runes) {
+				candidates = append(candidates, string(runes[:n]))
+			}
+		}
+		return candidates
+	case float64:
+		target := 0.0
+		if s.Minimum != nil && *s.Minimum > target {
+			target = *s.Minimum
+		}
+		if s.Maximum != nil && *s.Maximum < target {
+			target = *s.Maximum
+		}
+		var candidates []any
+		for _, c := range []float64{target, math.Trunc(target + (v-target)/2), math.Trunc(v), v - math.Copysign(1, v-target)} {
+			if c != v && math.Abs(c-target) < math.Abs(v-target) {
+				candidates = append(candidates, c)
+			}
+		}
+		return candidates
+	case bool:
+		if v {
+			return []any{false}
+		}
+	case []any:
+		items := s.Items
+		if items == nil {
+			items = &jsonSchema{}
+		}
+		minItems, _ := intRange(s.MinItems, nil, 0)
+		var candidates []any
+		if len(v) > minItems {
+			candidates = append(candidates, append([]any(nil), v[:minItems]...))
+			for i := range v {
+				candidates = append(candidates, append(append([]any(nil), v[:i]...), v[i+1:]...))
+			}
+		}
+		for i := range v {
+			for _, c := range items.shrink(v[i]) {
+				shrunk := append([]any(nil), v...)
+				shrunk[i] = c
+				candidates = append(candidates, shrunk)
+			}
+		}
+		return candidates
+	case map[string]any:
+		var candidates []any
+		keys := sortedKeys(v)
+		for _, key := range keys {
+			if !s.required(key) {
+				shrunk := copyObject(v)
+				delete(shrunk, key)
+				candidates = append(candidates, shrunk)
+			}
+		}
+		for _, key := range keys {
+			property := s.Properties[key]
+			if property == nil {
+				property = s.AdditionalProperties
+			}
+			if property == nil {
+				continue
+			}
+			for _, c := range property.shrink(v[key]) {
+				shrunk := copyObject(v)
+				shrunk[key] = c
+				candidates = append(candidates, shrunk)
+			}
+		}
+		return candidates
+	}
+	return nil
+}
+
+// validate returns a description of every way v does not match the schema. path is the JSONPath of v used in the descriptions
+func (s *jsonSchema) validate(v any, path string) []string {
+	if len(s.Enum) > 0 {
+		for _, value := range s.Enum {
+			if reflect.DeepEqual(value, v) {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: %s is not one of %s", path, mustMarshal(v), mustMarshal(s.Enum))}
+	}
+	var problems []string
+	switch s.Type {
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected string; got %s", path, mustMarshal(v))}
+		}
+		n := len([]rune(str))
+		if s.MinLength != nil && n < *s.MinLength {
+			problems = append(problems, fmt.Sprintf("%s: expected at least %d characters; got %d", path, *s.MinLength, n))
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			problems = append(problems, fmt.Sprintf("%s: expected at most %d characters; got %d", path, *s.MaxLength, n))
+		}
+	case "integer", "number":
+		n, ok := v.(float64)
+		if !ok || (s.Type == "integer" && n != math.Trunc(n)) {
+			return []string{fmt.Sprintf("%s: expected %s; got %s", path, s.Type, mustMarshal(v))}
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			problems = append(problems, fmt.Sprintf("%s: expected at least %v; got %v", path, *s.Minimum, n))
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			problems = append(problems, fmt.Sprintf("%s: expected at most %v; got %v", path, *s.Maximum, n))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return []string{fmt.Sprintf("%s: expected boolean; got %s", path, mustMarshal(v))}
+		}
+	case "null":
+		if v != nil {
+			return []string{fmt.Sprintf("%s: expected null; got %s", path, mustMarshal(v))}
+		}
+	case "array":
+		values, ok := v.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected array; got %s", path, mustMarshal(v))}
+		}
+		if s.MinItems != nil && len(values) < *s.MinItems {
+			problems = append(problems, fmt.Sprintf("%s: expected at least %d items; got %d", path, *s.MinItems, len(values)))
+		}
+		if s.MaxItems != nil && len(values) > *s.MaxItems {
+			problems = append(problems, fmt.Sprintf("%s: expected at most %d items; got %d", path, *s.MaxItems, len(values)))
+		}
+		if s.Items != nil {
+			for i, value := range values {
+				problems = append(problems, s.Items.validate(value, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case "object":
+		object, ok := v.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected object; got %s", path, mustMarshal(v))}
+		}
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing required property %q", path, name))
+			}
+		}
+		for _, name := range sortedKeys(s.Properties) {
+			if value, ok := object[name]; ok {
+				problems = append(problems, s.Properties[name].validate(value, path+"."+name)...)
+			}
+		}
+	}
+	return problems
+}
+
+// required returns true if the object property name is required
+func (s *jsonSchema) required(name string) bool {
+	for _, required := range s.Required {
+		if required == name {
+			return true
+		}
+	}
+	return false
+}
+
+// numberRange returns the range numbers are generated in, bounded by size around zero or around the bound of the schema closest to zero
+func (s *jsonSchema) numberRange(size int) (float64, float64) {
+	bound := float64(size * 10)
+	minimum, maximum := -bound, bound
+	if s.Minimum != nil {
+		minimum = math.Max(minimum, *s.Minimum)
+	}
+	if s.Maximum != nil {
+		maximum = math.Min(maximum, *s.Maximum)
+	}
+	if minimum > maximum {
+		if s.Minimum != nil && *s.Minimum > bound {
+			minimum = *s.Minimum
+			maximum = math.Min(valueOr(s.Maximum, minimum+bound), minimum+bound)
+		} else {
+			maximum = *s.Maximum
+			minimum = math.Max(valueOr(s.Minimum, maximum-bound), maximum-bound)
+		}
+	}
+	return minimum, maximum
+}
+
+// valueOr returns *p, or fallback if p is nil
+func valueOr(p *float64, fallback float64) float64 {
+	if p == nil {
+		return fallback
+	}
+	return *p
+}
+
+// intRange returns the range of lengths generated, bounded by size when there is no maximum
+func intRange(minimum, maximum *int, size int) (int, int) {
+	lo, hi := 0, size
+	if minimum != nil {
+		lo = *minimum
+	}
+	if maximum != nil {
+		hi = *maximum
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return lo, hi
+}
+
+// copyObject returns a shallow copy of a JSON object
+func copyObject(v map[string]any) map[string]any {
+	c := make(map[string]any, len(v))
+	for key, value := range v {
+		c[key] = value
+	}
+	return c
+}
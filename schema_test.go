@@ -0,0 +1,134 @@
+package httptesting
+
+import (
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type schemaExample struct {
+	ID       uint8             `json:"id"`
+	Name     string            `json:"name,omitempty"`
+	Created  time.Time         `json:"created"`
+	Data     []byte            `json:"data"`
+	Labels   map[string]string `json:"labels"`
+	Internal string            `json:"-"`
+	Score    float64
+}
+
+func TestSchema(t *testing.T) {
+	t.Parallel()
+	t.Run("generated values decode into the type", func(t *testing.T) {
+		t.Parallel()
+		schema, err := schemaForType(reflect.TypeOf(schemaExample{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(schema.Required, []string{"id", "created", "data", "labels", "Score"}) {
+			t.Fatalf("Unexpected required properties %v", schema.Required)
+		}
+		r := rand.New(rand.NewSource(1))
+		for size := 1; size <= 50; size++ {
+			value := schema.generate(r, size)
+			var example schemaExample
+			if err := json.Unmarshal(mustMarshal(value), &example); err != nil {
+				t.Fatalf("Error decoding %s: %s", mustMarshal(value), err.Error())
+			}
+		}
+	})
+
+	t.Run("generated values respect constraints", func(t *testing.T) {
+		t.Parallel()
+		schema, err := parseSchema([]byte(`{"type": "object", "required": ["n", "s", "a", "e"], "properties": {
+			"n": {"type": "integer", "minimum": 1000, "maximum": 1005},
+			"s": {"type": "string", "minLength": 2, "maxLength": 3},
+			"a": {"type": "array", "items": {"type": "boolean"}, "minItems": 1, "maxItems": 2},
+			"e": {"enum": ["x", "y"]}
+		}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 100; i++ {
+			value := schema.generate(r, 10).(map[string]any)
+			n, s, a, e := value["n"].(float64), value["s"].(string), value["a"].([]any), value["e"]
+			if n < 1000 || n > 1005 || len([]rune(s)) < 2 || len([]rune(s)) > 3 || len(a) < 1 || len(a) > 2 || (e != "x" && e != "y") {
+				t.Fatalf("Generated value does not match schema: %s", mustMarshal(value))
+			}
+		}
+	})
+
+	t.Run("shrinking respects constraints", func(t *testing.T) {
+		t.Parallel()
+		minimum := 5.0
+		schema := &jsonSchema{Type: "integer", Minimum: &minimum}
+		candidates := schema.shrink(100.0)
+		if len(candidates) == 0 || candidates[0] != 5.0 {
+			t.Fatalf("Expected to shrink towards the minimum first; got %v", candidates)
+		}
+		for _, c := range candidates {
+			if c.(float64) < minimum {
+				t.Fatalf("Shrunk value %v is below the minimum", c)
+			}
+		}
+		if candidates := schema.shrink(5.0); len(candidates) != 0 {
+			t.Fatalf("Expected the minimum not to shrink; got %v", candidates)
+		}
+	})
+	t.Run("recursive types", func(t *testing.T) {
+		t.Parallel()
+		_, err := schemaForType(reflect.TypeOf(schemaNode{}))
+		if err == nil || !strings.Contains(err.Error(), "recursive type httptesting.schemaNode") {
+			t.Fatalf("Expected a recursive type error; got %v", err)
+		}
+		type pair struct {
+			Left, Right schemaExample
+		}
+		if _, err := schemaForType(reflect.TypeOf(pair{})); err != nil {
+			t.Fatalf("Expected a type used twice to have a schema; got %s", err.Error())
+		}
+	})
+
+	t.Run("invalid ranges", func(t *testing.T) {
+		t.Parallel()
+		for schema, want := range map[string]string{
+			`{"type": "integer", "minimum": 0.5, "maximum": 0.7}`:                     "$: no integer between minimum 0.5 and maximum 0.7",
+			`{"type": "number", "minimum": 2, "maximum": 1}`:                          "$: minimum 2 is greater than maximum 1",
+			`{"type": "array", "items": {"type": "string", "minLength": -1}}`:         "$[*]: negative minLength or maxLength",
+			`{"type": "object", "properties": {"a": {"minItems": 3, "maxItems": 2}}}`: "$.a: minItems 3 is greater than maxItems 2",
+		} {
+			s, err := parseSchema([]byte(schema))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.check("$"); err == nil || err.Error() != want {
+				t.Errorf("Expected %q for %s; got %v", want, schema, err)
+			}
+		}
+	})
+
+	t.Run("large ranges", func(t *testing.T) {
+		t.Parallel()
+		s, err := parseSchema([]byte(`{"type": "integer", "minimum": -1e300, "maximum": 1e300}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.check("$"); err != nil {
+			t.Fatal(err)
+		}
+		r := rand.New(rand.NewSource(1))
+		for size := 1; size <= 50; size++ {
+			if v := s.generate(r, size).(float64); v < -1e300 || v > 1e300 {
+				t.Fatalf("Generated value %v is out of range", v)
+			}
+		}
+	})
+}
+
+// schemaNode recursive type with no finite schema
+type schemaNode struct {
+	Children []*schemaNode `json:"children"`
+}
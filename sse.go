@@ -0,0 +1,185 @@
+package httptesting
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event a Server-Sent Event read from a text/event-stream response
+type Event struct {
+	// ID value of the id field, or the last id received if the event did not set one
+	ID string
+
+	// Event name of the event. Empty for unnamed events, which browsers dispatch as "message"
+	Event string
+
+	// Data data lines of the event joined with newlines
+	Data string
+
+	// Retry reconnection time in milliseconds, or 0 if the event did not set one
+	Retry int
+}
+
+// EventStream Server-Sent Events stream returned by ExecuteSSE. Events are read one at a time while the handler is running
+type EventStream struct {
+	ht          *Httptester
+	execution   *streamExecution
+	lastEventID string
+
+	// partial fields of the event being read, kept between reads that time out in the middle of an event
+	partial Event
+	data    []string
+	hasData bool
+}
+
+// ExecuteSSE executes the current request in a streaming mode for text/event-stream endpoints.
+// The handler runs in its own goroutine until it returns or the stream is closed, and events become readable each time it calls Flush.
+// Response status and headers can be asserted as soon as ExecuteSSE returns. Close must be called to cancel the request
+func (ht *Httptester) ExecuteSSE() *EventStream {
+	ht.t.Helper()
+	return &EventStream{
+		ht:        ht,
+		execution: ht.executeStream(),
+	}
+}
+
+// next reads the next event from the stream. Lines of an event that was not complete before the timeout are kept for the next read
+func (s *EventStream) next(timeout time.Duration) (Event, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		line, err := s.execution.w.readLine(deadline)
+		if err != nil {
+			return Event{}, err
+		}
+		if line == "" {
+			event, hasData := s.partial, s.hasData
+			event.ID = s.lastEventID
+			event.Data = strings.Join(s.data, "\n")
+			s.partial, s.data, s.hasData = Event{}, nil, false
+			if !hasData {
+				continue
+			}
+			return event, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			s.partial.Event = value
+		case "data":
+			s.data = append(s.data, value)
+			s.hasData = true
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				s.lastEventID = value
+			}
+		case "retry":
+			if retry, err := strconv.Atoi(value); err == nil {
+				s.partial.Retry = retry
+			}
+		}
+	}
+}
+
+// failNext helper function to fail the test when no event could be read
+func (s *EventStream) failNext(err error, timeout time.Duration) {
+	s.ht.t.Helper()
+	s.ht.handleStreamPanic(s.execution)
+	if errors.Is(err, io.EOF) {
+		s.ht.t.Fatalf("Expected an event; stream ended")
+	}
+	s.ht.t.Fatalf("Expected an event within %s; got none", timeout)
+}
+
+// Next reads the next event, failing the test if no event is received within timeout or the stream ends
+func (s *EventStream) Next(timeout time.Duration) Event {
+	s.ht.t.Helper()
+	event, err := s.next(timeout)
+	if err != nil {
+		s.failNext(err, timeout)
+	}
+	return event
+}
+
+// LastEventID returns the last event id received. Send it in the Last-Event-ID header to test reconnection
+func (s *EventStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// AssertEvent asserts the next event received within timeout has the expected name and data
+func (s *EventStream) AssertEvent(timeout time.Duration, name, data string) {
+	s.ht.t.Helper()
+	defer s.ht.assertion("AssertEvent")()
+	event := s.Next(timeout)
+	if event.Event != name || event.Data != data {
+		s.ht.t.Fatalf("Expected event %q with data %q; got event %q with data %q", name, data, event.Event, event.Data)
+	}
+}
+
+// AssertEventID asserts the next event received within timeout has the expected id
+func (s *EventStream) AssertEventID(timeout time.Duration, id string) {
+	s.ht.t.Helper()
+	defer s.ht.assertion("AssertEventID")()
+	event := s.Next(timeout)
+	if event.ID != id {
+		s.ht.t.Fatalf("Expected event id %q; got %q", id, event.ID)
+	}
+}
+
+// AssertEventJSON decodes the data of the next event received within timeout into r and asserts the event has the expected name
+// and r is deeply equatable to expected
+func (s *EventStream) AssertEventJSON(timeout time.Duration, name string, r interface{}, expected interface{}) {
+	s.ht.t.Helper()
+	defer s.ht.assertion("AssertEventJSON")()
+	event := s.Next(timeout)
+	if event.Event != name {
+		s.ht.t.Fatalf("Expected event %q; got %q", name, event.Event)
+	}
+	if err := json.Unmarshal([]byte(event.Data), &r); err != nil {
+		s.ht.t.Fatalf("Error parsing event json: %s", err.Error())
+	}
+	if !reflect.DeepEqual(r, expected) {
+		s.ht.t.Fatalf("Expected %v; got %v", expected, r)
+	}
+}
+
+// AssertNoEvent asserts no event is received within timeout
+func (s *EventStream) AssertNoEvent(timeout time.Duration) {
+	s.ht.t.Helper()
+	defer s.ht.assertion("AssertNoEvent")()
+	event, err := s.next(timeout)
+	if err == nil {
+		s.ht.t.Fatalf("Expected no event; got event %q with data %q", event.Event, event.Data)
+	}
+}
+
+// AssertStreamEnds asserts the handler ends the stream within timeout without sending another event
+func (s *EventStream) AssertStreamEnds(timeout time.Duration) {
+	s.ht.t.Helper()
+	defer s.ht.assertion("AssertStreamEnds")()
+	event, err := s.next(timeout)
+	if err == nil {
+		s.ht.t.Fatalf("Expected stream to end; got event %q with data %q", event.Event, event.Data)
+	}
+	if !errors.Is(err, io.EOF) {
+		s.ht.t.Fatalf("Expected stream to end within %s", timeout)
+	}
+	s.ht.handleStreamPanic(s.execution)
+}
+
+// Close cancels the request context and fails the test if the handler does not return promptly
+func (s *EventStream) Close() {
+	s.ht.t.Helper()
+	if !s.execution.stop(defaultStreamTimeout) {
+		s.ht.t.Fatalf("Handler did not return within %s after the request was canceled", defaultStreamTimeout)
+	}
+	s.ht.handleStreamPanic(s.execution)
+}
@@ -0,0 +1,177 @@
+package httptesting
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+func sseHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		start := 1
+		if id, err := strconv.Atoi(r.Header.Get("Last-Event-ID")); err == nil {
+			start = id + 1
+		}
+		for i := start; i <= 3; i++ {
+			fmt.Fprintf(w, ": keep-alive\nid: %d\nevent: count\ndata: {\"count\": %d}\n\n", i, i)
+			flusher.Flush()
+		}
+		fmt.Fprint(w, "data: multi\ndata: line\n\n")
+		flusher.Flush()
+		<-r.Context().Done()
+	})
+}
+
+func TestExecuteSSE(t *testing.T) {
+	t.Parallel()
+	t.Run("reads events as they are flushed", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, sseHandler())
+		tester.Get("/events")
+		stream := tester.ExecuteSSE()
+		defer stream.Close()
+
+		tester.AssertStatusCode(http.StatusOK)
+		tester.AssertHeader("Content-Type", "text/event-stream")
+		stream.AssertEvent(time.Second, "count", `{"count": 1}`)
+		stream.AssertEventID(time.Second, "2")
+		stream.AssertEventJSON(time.Second, "count", &map[string]int{}, &map[string]int{"count": 3})
+		event := stream.Next(time.Second)
+		if event.Data != "multi\nline" || event.ID != "3" {
+			t.Errorf("Expected multi line event with id 3; got %+v", event)
+		}
+		stream.AssertNoEvent(10 * time.Millisecond)
+	})
+
+	t.Run("reconnects with Last-Event-ID", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, sseHandler())
+		tester.Get("/events")
+		stream := tester.ExecuteSSE()
+		stream.AssertEventID(time.Second, "1")
+		stream.Close()
+
+		tester.Get("/events")
+		tester.AddHeader("Last-Event-ID", stream.LastEventID())
+		stream = tester.ExecuteSSE()
+		defer stream.Close()
+		stream.AssertEventID(time.Second, "2")
+	})
+
+	t.Run("stream ends when handler returns", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "event: done\ndata: bye\n\n")
+		}))
+		tester.Get("/events")
+		stream := tester.ExecuteSSE()
+		defer stream.Close()
+		stream.AssertEvent(time.Second, "done", "bye")
+		stream.AssertStreamEnds(time.Second)
+	})
+
+	t.Run("missing event fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, sseHandler())
+		tester.Get("/events")
+		stream := tester.ExecuteSSE()
+		defer stream.Close()
+		stream.AssertEvent(time.Second, "count", `{"count": 1}`)
+
+		defer assertFatal(t)
+		stream.AssertEvent(time.Second, "other", "data")
+	})
+
+	t.Run("handler panic fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			panic("stream failed")
+		}))
+		tester.Get("/events")
+		defer assertFatal(t)
+		stream := tester.ExecuteSSE()
+		stream.Next(time.Second)
+	})
+
+	t.Run("partial event is kept after a timeout", func(t *testing.T) {
+		t.Parallel()
+		proceed := make(chan struct{})
+		tester := New(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			flusher := w.(http.Flusher)
+			fmt.Fprint(w, "event: tick\n")
+			flusher.Flush()
+			<-proceed
+			fmt.Fprint(w, "data: 1\n\n")
+			flusher.Flush()
+			<-r.Context().Done()
+		}))
+		tester.Get("/events")
+		stream := tester.ExecuteSSE()
+		defer stream.Close()
+
+		stream.AssertNoEvent(10 * time.Millisecond)
+		close(proceed)
+		stream.AssertEvent(time.Second, "tick", "1")
+	})
+
+	t.Run("expected panic is recorded", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			panic("stream failed")
+		}))
+		tester.Get("/events")
+		tester.ExpectPanic()
+		stream := tester.ExecuteSSE()
+		stream.AssertStreamEnds(time.Second)
+		stream.Close()
+		tester.AssertPanicValue("stream failed")
+	})
+
+	t.Run("no panic is recorded", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, sseHandler())
+		tester.Get("/events")
+		stream := tester.ExecuteSSE()
+		stream.AssertEvent(time.Second, "count", `{"count": 1}`)
+		tester.AssertNoPanic()
+		stream.Close()
+		tester.AssertNoPanic()
+	})
+
+	t.Run("handler is stopped when the test finishes", func(t *testing.T) {
+		t.Parallel()
+		stopped := make(chan struct{})
+		t.Run("stream left open", func(t *testing.T) {
+			tester := New(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				<-r.Context().Done()
+				close(stopped)
+			}))
+			tester.Get("/events")
+			tester.ExecuteSSE()
+		})
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatalf("Expected the handler to be stopped by the cleanup of the test")
+		}
+	})
+}
@@ -0,0 +1,310 @@
+package httptesting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultStreamTimeout time to wait for a streaming handler to write its headers or return after being canceled
+const defaultStreamTimeout = 5 * time.Second
+
+// errStreamTimeout returned when no data was flushed by the handler before the deadline
+var errStreamTimeout = errors.New("timed out waiting for data")
+
+// streamWriter http.ResponseWriter and http.Flusher that makes the data written by a handler readable while the handler is still running.
+// Written data only becomes readable once the handler calls Flush or returns, the same as a client would receive it
+type streamWriter struct {
+	header http.Header
+	start  time.Time
+
+	mu          sync.Mutex
+	code        int
+	sentHeader  http.Header
+	wroteHeader bool
+	pending     []byte
+	writes      int
+	flushed     []byte
+	chunks      []Chunk
+	flushes     int
+	read        int
+	done        bool
+	panic       *HandlerPanic
+	changed     chan struct{}
+}
+
+// newStreamWriter returns a new streamWriter
+func newStreamWriter() *streamWriter {
+	return &streamWriter{
+		header:  make(http.Header),
+		start:   time.Now(),
+		changed: make(chan struct{}),
+	}
+}
+
+// Header returns the response headers to be sent by WriteHeader
+func (w *streamWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader sends the status code and a snapshot of the response headers
+func (w *streamWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writeHeaderLocked(code)
+}
+
+// writeHeaderLocked sends the headers if they have not been sent. Must be called with the lock held
+func (w *streamWriter) writeHeaderLocked(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.code = code
+	w.sentHeader = w.header.Clone()
+	w.wroteHeader = true
+	w.broadcast()
+}
+
+// Write buffers p until the next Flush
+func (w *streamWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writeHeaderLocked(http.StatusOK)
+	w.pending = append(w.pending, p...)
+	w.writes++
+	return len(p), nil
+}
+
+// Flush makes all written data readable
+func (w *streamWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writeHeaderLocked(http.StatusOK)
+	w.flushes++
+	w.flushLocked(false)
+}
+
+// flushLocked moves pending data to the readable buffer and records it as a chunk. Must be called with the lock held
+func (w *streamWriter) flushLocked(final bool) {
+	if len(w.pending) == 0 {
+		return
+	}
+	now := time.Now()
+	w.chunks = append(w.chunks, Chunk{
+		Data:    w.pending,
+		Writes:  w.writes,
+		Time:    now,
+		Elapsed: now.Sub(w.start),
+		Final:   final,
+	})
+	w.flushed = append(w.flushed, w.pending...)
+	w.pending = nil
+	w.writes = 0
+	w.broadcast()
+}
+
+// finish flushes any remaining data and marks the stream as complete. p is the panic recovered if the handler panicked
+func (w *streamWriter) finish(p *HandlerPanic) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writeHeaderLocked(http.StatusOK)
+	w.flushLocked(true)
+	w.done = true
+	w.panic = p
+	w.broadcast()
+}
+
+// broadcast wakes every goroutine waiting for a change. Must be called with the lock held
+func (w *streamWriter) broadcast() {
+	close(w.changed)
+	w.changed = make(chan struct{})
+}
+
+// wait blocks until ready returns true or the deadline passes. ready is called with the lock held
+func (w *streamWriter) wait(deadline time.Time, ready func() bool) bool {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	for {
+		w.mu.Lock()
+		if ready() {
+			w.mu.Unlock()
+			return true
+		}
+		changed := w.changed
+		w.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return false
+		}
+	}
+}
+
+// readLine returns the next line of flushed data without its line ending.
+// Returns io.EOF when the handler returned and every line was read, or errStreamTimeout if no line was flushed before the deadline
+func (w *streamWriter) readLine(deadline time.Time) (string, error) {
+	var line string
+	var err error
+	ok := w.wait(deadline, func() bool {
+		unread := w.flushed[w.read:]
+		if i := bytes.IndexByte(unread, '\n'); i >= 0 {
+			line = string(bytes.TrimSuffix(unread[:i], []byte("\r")))
+			w.read += i + 1
+			return true
+		}
+		if w.done {
+			if len(unread) > 0 {
+				line = string(unread)
+				w.read += len(unread)
+				return true
+			}
+			err = io.EOF
+			return true
+		}
+		return false
+	})
+	if !ok {
+		return "", errStreamTimeout
+	}
+	return line, err
+}
+
+// streamExecution handler running in its own goroutine with a cancelable request context
+type streamExecution struct {
+	w      *streamWriter
+	start  time.Time
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// req and requestBody request served by the handler, dumped if the handler panics
+	req         *http.Request
+	requestBody []byte
+	// expectPanic is set to true when the request was expected to make the handler panic
+	expectPanic bool
+}
+
+// executeStream starts the current request in a new goroutine and waits for the handler to write its headers.
+// The response headers are available to the Assert* functions once it returns.
+// The handler is stopped when the test finishes if the stream was not closed
+func (ht *Httptester) executeStream() *streamExecution {
+	ht.t.Helper()
+	expectPanic := ht.expectPanic
+	req, requestBody, release := ht.prepareRequest()
+	ctx, cancel := context.WithCancel(req.Context())
+	req = req.WithContext(ctx)
+
+	e := &streamExecution{
+		w:     newStreamWriter(),
+		start: time.Now(),
+		cancel: func() {
+			cancel()
+			release()
+		},
+		done:        make(chan struct{}),
+		req:         req,
+		requestBody: requestBody,
+		expectPanic: expectPanic,
+	}
+	ht.stream = e
+	go func() {
+		defer close(e.done)
+		e.w.finish(ht.serve(e.w, req))
+	}()
+	ht.t.Cleanup(func() { e.stop(defaultStreamTimeout) })
+
+	if !e.w.wait(time.Now().Add(defaultStreamTimeout), func() bool { return e.w.wroteHeader }) {
+		e.cancel()
+		ht.t.Fatalf("Handler did not write response headers within %s", defaultStreamTimeout)
+	}
+
+	ht.requestExecuted = true
+	ht.state.Request = nil
+	ht.state.Response = e.w.response(req, false)
+	ht.recordExchange(&Exchange{
+		Method:         req.Method,
+		URL:            req.URL.String(),
+		RequestHeader:  req.Header.Clone(),
+		RequestBody:    requestBody,
+		StatusCode:     ht.state.Response.StatusCode,
+		ResponseHeader: ht.state.Response.Header.Clone(),
+		Start:          e.start,
+	})
+	select {
+	case <-e.done:
+		ht.handleStreamPanic(e)
+	default:
+	}
+	ht.runResponseHooks(ht.exchange)
+	return e
+}
+
+// handleStreamPanic helper function to record a panic from the handler of a stream in State and fail the test
+// with the request that caused it and the stack trace, unless the panic was expected
+func (ht *Httptester) handleStreamPanic(e *streamExecution) {
+	ht.t.Helper()
+	p := e.panicked()
+	if p == nil && ht.stream != e {
+		return
+	}
+	ht.handlePanic(p, e.expectPanic, e.req, e.requestBody)
+}
+
+// syncStreamPanic helper function to record the panic of the handler in State when the previous request was executed with ExecuteSSE.
+// If wait is true the handler is given up to defaultStreamTimeout to return first
+func (ht *Httptester) syncStreamPanic(wait bool) {
+	if ht.stream == nil {
+		return
+	}
+	if wait {
+		select {
+		case <-ht.stream.done:
+		case <-time.After(defaultStreamTimeout):
+		}
+	}
+	ht.state.Panic = ht.stream.panicked()
+}
+
+// response returns the response sent so far. The body contains the flushed data if withBody is true and is empty otherwise
+func (w *streamWriter) response(req *http.Request, withBody bool) *http.Response {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var body io.ReadCloser = http.NoBody
+	if withBody {
+		body = io.NopCloser(bytes.NewReader(w.flushed))
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", w.code, http.StatusText(w.code)),
+		StatusCode:    w.code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        w.sentHeader.Clone(),
+		Body:          body,
+		ContentLength: -1,
+		Request:       req,
+	}
+}
+
+// stop cancels the request context and waits for the handler to return. Returns false if the handler did not return before timeout
+func (e *streamExecution) stop(timeout time.Duration) bool {
+	e.cancel()
+	select {
+	case <-e.done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// panicked returns the panic recovered if the handler panicked
+func (e *streamExecution) panicked() *HandlerPanic {
+	e.w.mu.Lock()
+	defer e.w.mu.Unlock()
+	return e.w.panic
+}
@@ -0,0 +1,28 @@
+package httptesting
+
+import "github.com/hunterwilkins2/httptesting/internal/util"
+
+// TestingT methods of testing.T used by httptesting. Implemented by *testing.T, *testing.B and *testing.F
+type TestingT = util.TestingT
+
+// MinimalT implementations of TestingT that only provide Fatalf, e.g. a custom runner outside of go test
+type MinimalT = util.MinimalT
+
+// AdaptT returns t as a TestingT so it can be passed to New. Methods t does not implement fall back to:
+// Helper and Logf do nothing, Errorf calls Fatalf, Cleanup discards the function and Name returns an empty string
+func AdaptT(t MinimalT) TestingT {
+	return util.Adapt(t)
+}
+
+// testFailed helper function to report whether the test wrapped by t has failed
+func testFailed(t util.TestingT) bool {
+	switch wrapped := t.(type) {
+	case *reportingT:
+		return testFailed(wrapped.TestingT)
+	case *stepT:
+		return testFailed(wrapped.TestingT)
+	case *eventuallyT:
+		return testFailed(wrapped.TestingT)
+	}
+	return util.Failed(t)
+}
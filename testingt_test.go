@@ -0,0 +1,71 @@
+package httptesting
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+)
+
+// minimalT TestingT that only implements Fatalf
+type minimalT struct {
+	failures []string
+}
+
+func (t *minimalT) Fatalf(format string, args ...any) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAdaptT(t *testing.T) {
+	t.Parallel()
+	t.Run("testing.T is returned as is", func(t *testing.T) {
+		t.Parallel()
+		if AdaptT(t) != TestingT(t) {
+			t.Fatalf("Expected AdaptT to return t")
+		}
+	})
+
+	t.Run("minimal implementation", func(t *testing.T) {
+		t.Parallel()
+		minimal := &minimalT{}
+		adapted := AdaptT(minimal)
+		adapted.Helper()
+		adapted.Logf("ignored")
+		adapted.Cleanup(func() { t.Fatalf("Expected cleanup to be discarded") })
+		adapted.Errorf("error %d", 1)
+		adapted.Fatalf("fatal %d", 2)
+		if adapted.Name() != "" {
+			t.Fatalf("Expected an empty name; got %q", adapted.Name())
+		}
+		if len(minimal.failures) != 2 || minimal.failures[0] != "error 1" || minimal.failures[1] != "fatal 2" {
+			t.Fatalf("Expected Errorf and Fatalf to call Fatalf; got %q", minimal.failures)
+		}
+	})
+
+	t.Run("adapted httptester", func(t *testing.T) {
+		t.Parallel()
+		minimal := &minimalT{}
+		tester := New(AdaptT(minimal), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}))
+		tester.Get("/")
+		tester.Execute()
+		tester.AssertStatusCode(http.StatusOK)
+		if len(minimal.failures) != 1 {
+			t.Fatalf("Expected 1 failure; got %q", minimal.failures)
+		}
+	})
+
+	t.Run("mock cleanups", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		var order []int
+		mockT.Cleanup(func() { order = append(order, 1) })
+		mockT.Cleanup(func() { order = append(order, 2) })
+		mockT.RunCleanups()
+		if len(order) != 2 || order[0] != 2 || order[1] != 1 {
+			t.Fatalf("Expected cleanups to run in reverse order; got %v", order)
+		}
+	})
+}
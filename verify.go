@@ -0,0 +1,34 @@
+package httptesting
+
+import (
+	"strings"
+)
+
+// Verifier is implemented by test dependencies with expectations checked at the end of a test, such as mock.Server
+type Verifier interface {
+	Verify() error
+}
+
+// AddVerifier registers a dependency whose expectations are asserted by AssertExpectations.
+// The expectations are also asserted automatically when the test finishes
+func (ht *Httptester) AddVerifier(v Verifier) {
+	ht.verifiers = append(ht.verifiers, v)
+	if len(ht.verifiers) == 1 {
+		ht.t.Cleanup(ht.AssertExpectations)
+	}
+}
+
+// AssertExpectations asserts every dependency registered with AddVerifier had its expectations met
+func (ht *Httptester) AssertExpectations() {
+	ht.t.Helper()
+	defer ht.assertion("AssertExpectations")()
+	var messages []string
+	for _, v := range ht.verifiers {
+		if err := v.Verify(); err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+	if len(messages) > 0 {
+		ht.t.Fatalf("Unmet expectations:\n%s", strings.Join(messages, "\n"))
+	}
+}
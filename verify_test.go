@@ -0,0 +1,52 @@
+package httptesting
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+	"github.com/hunterwilkins2/httptesting/mock"
+)
+
+func TestAssertExpectations(t *testing.T) {
+	t.Parallel()
+	proxy := func(s *mock.Server) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			res, err := s.Client().Get(s.URL() + "/users/1")
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			defer res.Body.Close()
+			w.WriteHeader(res.StatusCode)
+		})
+	}
+
+	t.Run("met expectations pass", func(t *testing.T) {
+		t.Parallel()
+		s := mock.NewServer(t)
+		s.Expect(http.MethodGet, "/users/1").Respond(http.StatusOK, `{"id": 1}`)
+
+		tester := New(t, proxy(s))
+		tester.AddVerifier(s)
+		tester.Get("/profile")
+		tester.Execute()
+		tester.AssertStatusCode(http.StatusOK)
+		tester.AssertExpectations()
+	})
+
+	t.Run("unmet expectations fail test", func(t *testing.T) {
+		t.Parallel()
+		s := mock.NewServer(t)
+		s.Expect(http.MethodGet, "/users/1").Times(2)
+
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, proxy(s))
+		tester.AddVerifier(s)
+		tester.Get("/profile")
+		tester.Execute()
+
+		defer assertFatal(t)
+		tester.AssertExpectations()
+	})
+}
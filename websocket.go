@@ -0,0 +1,378 @@
+package httptesting
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"time"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+	"github.com/hunterwilkins2/httptesting/internal/websocket"
+)
+
+// WebSocket message types
+const (
+	// TextMessage UTF-8 text message
+	TextMessage = int(websocket.OpText)
+	// BinaryMessage binary message
+	BinaryMessage = int(websocket.OpBinary)
+)
+
+// WebSocket close codes passed to Close and AssertCloseCode
+const (
+	// CloseNormalClosure the purpose of the connection was fulfilled
+	CloseNormalClosure = websocket.CloseNormal
+	// CloseGoingAway the endpoint is going away, e.g. a server shutting down
+	CloseGoingAway = websocket.CloseGoingAway
+	// CloseProtocolError the endpoint received a frame that violates the protocol
+	CloseProtocolError = websocket.CloseProtocolError
+	// CloseNoStatusReceived the close frame did not contain a close code
+	CloseNoStatusReceived = websocket.CloseNoStatus
+)
+
+// errWebSocketClosed returned when a message is read after the connection was closed
+var errWebSocketClosed = errors.New("websocket closed")
+
+// Message a data message received on a WebSocket
+type Message struct {
+	// Type TextMessage or BinaryMessage
+	Type int
+
+	// Data payload of the message
+	Data []byte
+}
+
+// WebSocket client side of a WebSocket connection to the handler under test returned by ExecuteWebSocket
+type WebSocket struct {
+	ht     *Httptester
+	server *httptest.Server
+	conn   net.Conn
+	reader *bufio.Reader
+
+	messages  []Message
+	partial   *Message
+	pongs     [][]byte
+	closed    bool
+	closeSent bool
+	closeCode int
+}
+
+// ExecuteWebSocket performs a WebSocket opening handshake for the current request. The handler is served over a local
+// server so the connection can be hijacked, and the request carries the headers and cookies of the session the same as Execute.
+// The handshake response can be asserted with the Assert* functions, e.g. AssertStatusCode(http.StatusSwitchingProtocols).
+// Close performs the closing handshake. The connection and the local server are also closed when the test finishes
+func (ht *Httptester) ExecuteWebSocket() *WebSocket {
+	ht.t.Helper()
+	req, _, release := ht.prepareRequest()
+	release()
+	key, err := websocket.NewKey()
+	if err != nil {
+		ht.t.Fatalf("Error creating websocket key: %s", err.Error())
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	ws := &WebSocket{ht: ht, server: httptest.NewServer(ht.handler)}
+	ws.conn, err = net.Dial("tcp", ws.server.Listener.Addr().String())
+	if err != nil {
+		ws.server.Close()
+		ht.t.Fatalf("Error connecting to websocket server: %s", err.Error())
+	}
+	ws.reader = bufio.NewReader(ws.conn)
+	ht.t.Cleanup(ws.shutdown)
+
+	start := time.Now()
+	req.Host = ws.server.Listener.Addr().String()
+	if err := req.Write(ws.conn); err != nil {
+		ws.shutdown()
+		ht.t.Fatalf("Error sending websocket handshake: %s", err.Error())
+	}
+	if err := ws.conn.SetReadDeadline(start.Add(defaultStreamTimeout)); err != nil {
+		ws.shutdown()
+		ht.t.Fatalf(err.Error())
+	}
+	res, err := http.ReadResponse(ws.reader, req)
+	if err != nil {
+		ws.shutdown()
+		ht.t.Fatalf("Error reading websocket handshake response: %s", err.Error())
+	}
+
+	var body []byte
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		body, err = io.ReadAll(res.Body)
+		if err != nil {
+			ws.shutdown()
+			ht.t.Fatalf("Error reading websocket handshake response: %s", err.Error())
+		}
+		ws.closed = true
+	} else if res.Header.Get("Sec-WebSocket-Accept") != websocket.AcceptKey(key) {
+		ws.shutdown()
+		ht.t.Fatalf("Invalid Sec-WebSocket-Accept header %q", res.Header.Get("Sec-WebSocket-Accept"))
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	ht.requestExecuted = true
+	ht.state.Response = res
+	ht.state.Request = nil
+	ht.recordExchange(&Exchange{
+		Method:         req.Method,
+		URL:            req.URL.String(),
+		RequestHeader:  req.Header.Clone(),
+		StatusCode:     res.StatusCode,
+		ResponseHeader: res.Header.Clone(),
+		ResponseBody:   body,
+		Start:          start,
+		Duration:       time.Since(start),
+	})
+	ht.runResponseHooks(ht.exchange)
+	return ws
+}
+
+// shutdown closes the connection and the local server. It is safe to call more than once,
+// and is called when the test finishes so the server and handler do not outlive a test that failed before Close
+func (ws *WebSocket) shutdown() {
+	ws.conn.Close()
+	ws.server.Close()
+}
+
+// t returns the TestingT of the httptester
+func (ws *WebSocket) t() util.TestingT {
+	return ws.ht.t
+}
+
+// write sends a single frame
+func (ws *WebSocket) write(opcode byte, payload []byte) {
+	ws.t().Helper()
+	if ws.closed {
+		ws.t().Fatalf("Cannot send on a closed websocket")
+	}
+	if err := websocket.WriteFrame(ws.conn, websocket.Frame{Fin: true, Opcode: opcode, Payload: payload}, true); err != nil {
+		ws.t().Fatalf("Error sending websocket frame: %s", err.Error())
+	}
+}
+
+// SendText sends a text message
+func (ws *WebSocket) SendText(text string) {
+	ws.t().Helper()
+	ws.write(websocket.OpText, []byte(text))
+}
+
+// SendBinary sends a binary message
+func (ws *WebSocket) SendBinary(data []byte) {
+	ws.t().Helper()
+	ws.write(websocket.OpBinary, data)
+}
+
+// SendJSON encodes v as JSON and sends it as a text message
+func (ws *WebSocket) SendJSON(v interface{}) {
+	ws.t().Helper()
+	data, err := util.EncodeJSON(v)
+	if err != nil {
+		ws.t().Fatalf("Error encoding websocket message: %s", err.Error())
+	}
+	ws.write(websocket.OpText, data)
+}
+
+// readFrame reads frames until a complete data message, pong or close frame is received, answering pings along the way.
+// The fragments of a message interrupted by a control frame are kept for the next read
+func (ws *WebSocket) readFrame(deadline time.Time) error {
+	if ws.closed {
+		return errWebSocketClosed
+	}
+	if err := ws.conn.SetReadDeadline(deadline); err != nil {
+		return err
+	}
+	for {
+		frame, err := websocket.ReadFrame(ws.reader)
+		if err != nil {
+			return err
+		}
+		switch frame.Opcode {
+		case websocket.OpPing:
+			if err := websocket.WriteFrame(ws.conn, websocket.Frame{Fin: true, Opcode: websocket.OpPong, Payload: frame.Payload}, true); err != nil {
+				return err
+			}
+			continue
+		case websocket.OpPong:
+			ws.pongs = append(ws.pongs, frame.Payload)
+			return nil
+		case websocket.OpClose:
+			ws.closeCode, _ = websocket.ParseClosePayload(frame.Payload)
+			ws.closed = true
+			if !ws.closeSent {
+				ws.closeSent = true
+				return websocket.WriteFrame(ws.conn, websocket.Frame{Fin: true, Opcode: websocket.OpClose, Payload: frame.Payload}, true)
+			}
+			return nil
+		case websocket.OpContinuation:
+			if ws.partial == nil {
+				return errors.New("unexpected continuation frame")
+			}
+			ws.partial.Data = append(ws.partial.Data, frame.Payload...)
+		default:
+			ws.partial = &Message{Type: int(frame.Opcode), Data: frame.Payload}
+		}
+		if frame.Fin && ws.partial != nil {
+			ws.messages = append(ws.messages, *ws.partial)
+			ws.partial = nil
+			return nil
+		}
+	}
+}
+
+// Receive returns the next data message, failing the test if none is received within timeout
+func (ws *WebSocket) Receive(timeout time.Duration) Message {
+	ws.t().Helper()
+	deadline := time.Now().Add(timeout)
+	for len(ws.messages) == 0 {
+		if err := ws.readFrame(deadline); err != nil {
+			ws.failRead(err, timeout)
+		}
+		if ws.closed && len(ws.messages) == 0 {
+			ws.t().Fatalf("Expected a websocket message; connection closed with code %d", ws.closeCode)
+		}
+	}
+	message := ws.messages[0]
+	ws.messages = ws.messages[1:]
+	return message
+}
+
+// failRead helper function to fail the test when a frame could not be read
+func (ws *WebSocket) failRead(err error, timeout time.Duration) {
+	ws.t().Helper()
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		ws.t().Fatalf("Expected a websocket message within %s; got none", timeout)
+	}
+	ws.t().Fatalf("Error reading websocket frame: %s", err.Error())
+}
+
+// ReceiveText returns the next message, failing the test if it is not a text message or none is received within timeout
+func (ws *WebSocket) ReceiveText(timeout time.Duration) string {
+	ws.t().Helper()
+	message := ws.Receive(timeout)
+	if message.Type != TextMessage {
+		ws.t().Fatalf("Expected a text message; got a binary message")
+	}
+	return string(message.Data)
+}
+
+// AssertText asserts the next message received within timeout is a text message equal to expected
+func (ws *WebSocket) AssertText(timeout time.Duration, expected string) {
+	ws.t().Helper()
+	defer ws.ht.assertion("AssertText")()
+	if text := ws.ReceiveText(timeout); text != expected {
+		ws.t().Fatalf("Expected %q; got %q", expected, text)
+	}
+}
+
+// AssertBinary asserts the next message received within timeout is a binary message equal to expected
+func (ws *WebSocket) AssertBinary(timeout time.Duration, expected []byte) {
+	ws.t().Helper()
+	defer ws.ht.assertion("AssertBinary")()
+	message := ws.Receive(timeout)
+	if message.Type != BinaryMessage {
+		ws.t().Fatalf("Expected a binary message; got a text message")
+	}
+	if !bytes.Equal(message.Data, expected) {
+		ws.t().Fatalf("Expected %v; got %v", expected, message.Data)
+	}
+}
+
+// AssertJSON decodes the next message received within timeout into r and asserts r is deeply equatable to expected
+func (ws *WebSocket) AssertJSON(timeout time.Duration, r interface{}, expected interface{}) {
+	ws.t().Helper()
+	defer ws.ht.assertion("AssertJSON")()
+	message := ws.Receive(timeout)
+	if err := json.Unmarshal(message.Data, &r); err != nil {
+		ws.t().Fatalf("Error parsing websocket message json: %s", err.Error())
+	}
+	if !reflect.DeepEqual(r, expected) {
+		ws.t().Fatalf("Expected %v; got %v", expected, r)
+	}
+}
+
+// AssertNoMessage asserts no message is received within timeout
+func (ws *WebSocket) AssertNoMessage(timeout time.Duration) {
+	ws.t().Helper()
+	defer ws.ht.assertion("AssertNoMessage")()
+	if len(ws.messages) == 0 && !ws.closed {
+		deadline := time.Now().Add(timeout)
+		for len(ws.messages) == 0 && !ws.closed {
+			var netErr net.Error
+			if err := ws.readFrame(deadline); errors.As(err, &netErr) && netErr.Timeout() {
+				return
+			} else if err != nil {
+				ws.t().Fatalf("Error reading websocket frame: %s", err.Error())
+			}
+		}
+	}
+	if len(ws.messages) > 0 {
+		ws.t().Fatalf("Expected no message; got %q", ws.messages[0].Data)
+	}
+}
+
+// Ping sends a ping with payload and asserts the handler answers with a matching pong within timeout.
+// Data messages received while waiting are kept for the next Receive
+func (ws *WebSocket) Ping(timeout time.Duration, payload string) {
+	ws.t().Helper()
+	defer ws.ht.assertion("Ping")()
+	ws.write(websocket.OpPing, []byte(payload))
+	deadline := time.Now().Add(timeout)
+	for {
+		for i, pong := range ws.pongs {
+			if string(pong) == payload {
+				ws.pongs = append(ws.pongs[:i], ws.pongs[i+1:]...)
+				return
+			}
+		}
+		if err := ws.readFrame(deadline); err != nil {
+			ws.t().Fatalf("Expected pong %q within %s: %s", payload, timeout, err.Error())
+		}
+		if ws.closed {
+			ws.t().Fatalf("Expected pong %q; connection closed with code %d", payload, ws.closeCode)
+		}
+	}
+}
+
+// AssertCloseCode asserts the handler closes the connection with code within timeout.
+// Data messages received before the close frame are discarded
+func (ws *WebSocket) AssertCloseCode(timeout time.Duration, code int) {
+	ws.t().Helper()
+	defer ws.ht.assertion("AssertCloseCode")()
+	deadline := time.Now().Add(timeout)
+	for !ws.closed {
+		if err := ws.readFrame(deadline); err != nil {
+			ws.t().Fatalf("Expected websocket to close with code %d within %s: %s", code, timeout, err.Error())
+		}
+		ws.messages = nil
+	}
+	if ws.closeCode != code {
+		ws.t().Fatalf("Expected close code %d; got %d", code, ws.closeCode)
+	}
+}
+
+// Close sends a close frame with code, e.g. CloseNormalClosure, waits for the handler to answer and closes the connection and local server
+func (ws *WebSocket) Close(code int) {
+	ws.t().Helper()
+	defer ws.shutdown()
+	if ws.closed {
+		return
+	}
+	ws.write(websocket.OpClose, websocket.ClosePayload(code, ""))
+	ws.closeSent = true
+	deadline := time.Now().Add(defaultStreamTimeout)
+	for !ws.closed {
+		if err := ws.readFrame(deadline); err != nil {
+			return
+		}
+	}
+}
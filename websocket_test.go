@@ -0,0 +1,195 @@
+package httptesting
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hunterwilkins2/httptesting/internal/util"
+	"github.com/hunterwilkins2/httptesting/internal/websocket"
+)
+
+// upgradeWebSocket completes the server side of the opening handshake and hijacks the connection.
+// Frames read from the returned reader are masked by the client
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	_, err = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + websocket.AcceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+	if err == nil {
+		err = rw.Flush()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, rw, nil
+}
+
+func websocketHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+	})
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "abc" || r.Header.Get("X-Client") != "test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, rw, err := upgradeWebSocket(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			frame, err := websocket.ReadFrame(rw)
+			if err != nil {
+				return
+			}
+			var reply websocket.Frame
+			switch {
+			case frame.Opcode == websocket.OpPing:
+				reply = websocket.Frame{Fin: true, Opcode: websocket.OpPong, Payload: frame.Payload}
+			case frame.Opcode == websocket.OpClose:
+				_ = websocket.WriteFrame(conn, frame, false)
+				return
+			case string(frame.Payload) == "bye":
+				reply = websocket.Frame{Fin: true, Opcode: websocket.OpClose, Payload: websocket.ClosePayload(4000, "bye")}
+			case string(frame.Payload) == "split":
+				if websocket.WriteFrame(conn, websocket.Frame{Opcode: websocket.OpText, Payload: []byte("sp")}, false) != nil {
+					return
+				}
+				reply = websocket.Frame{Fin: true, Opcode: websocket.OpContinuation, Payload: []byte("lit")}
+			case string(frame.Payload) == "interleave":
+				if websocket.WriteFrame(conn, websocket.Frame{Opcode: websocket.OpText, Payload: []byte("inter")}, false) != nil ||
+					websocket.WriteFrame(conn, websocket.Frame{Fin: true, Opcode: websocket.OpPong, Payload: []byte("unsolicited")}, false) != nil {
+					return
+				}
+				reply = websocket.Frame{Fin: true, Opcode: websocket.OpContinuation, Payload: []byte("leave")}
+			default:
+				reply = frame
+			}
+			if websocket.WriteFrame(conn, reply, false) != nil {
+				return
+			}
+		}
+	})
+	return mux
+}
+
+func newWebSocket(t *testing.T, tester *Httptester) *WebSocket {
+	t.Helper()
+	tester.Get("/login")
+	tester.Execute()
+	tester.Get("/ws")
+	tester.AddHeader("X-Client", "test")
+	ws := tester.ExecuteWebSocket()
+	tester.AssertStatusCode(http.StatusSwitchingProtocols)
+	return ws
+}
+
+func TestExecuteWebSocket(t *testing.T) {
+	t.Parallel()
+	t.Run("sends and receives messages", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, websocketHandler())
+		ws := newWebSocket(t, tester)
+		defer ws.Close(CloseNormalClosure)
+
+		ws.SendText("hello")
+		ws.AssertText(time.Second, "hello")
+		ws.SendBinary([]byte{1, 2, 3})
+		ws.AssertBinary(time.Second, []byte{1, 2, 3})
+		ws.SendJSON(map[string]int{"count": 1})
+		ws.AssertJSON(time.Second, &map[string]int{}, &map[string]int{"count": 1})
+		ws.SendText("split")
+		ws.AssertText(time.Second, "split")
+		ws.Ping(time.Second, "ping")
+		ws.AssertNoMessage(10 * time.Millisecond)
+	})
+
+	t.Run("fragmented message interrupted by a control frame", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, websocketHandler())
+		ws := newWebSocket(t, tester)
+		defer ws.Close(CloseNormalClosure)
+
+		ws.SendText("interleave")
+		ws.AssertText(time.Second, "interleave")
+	})
+
+	t.Run("asserts close code", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, websocketHandler())
+		ws := newWebSocket(t, tester)
+		defer ws.Close(CloseNormalClosure)
+
+		ws.SendText("bye")
+		ws.AssertCloseCode(time.Second, 4000)
+	})
+
+	t.Run("rejected handshake can be asserted", func(t *testing.T) {
+		t.Parallel()
+		tester := New(t, websocketHandler())
+		tester.Get("/ws")
+		ws := tester.ExecuteWebSocket()
+		defer ws.Close(CloseNormalClosure)
+		tester.AssertStatusCode(http.StatusUnauthorized)
+	})
+
+	t.Run("unexpected message fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, websocketHandler())
+		ws := newWebSocket(t, tester)
+		defer ws.Close(CloseNormalClosure)
+
+		ws.SendText("hello")
+		defer assertFatal(t)
+		ws.AssertText(time.Second, "goodbye")
+	})
+
+	t.Run("missing message fails test", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		tester := New(&mockT, websocketHandler())
+		ws := newWebSocket(t, tester)
+		defer ws.Close(CloseNormalClosure)
+
+		defer assertFatal(t)
+		ws.Receive(10 * time.Millisecond)
+	})
+	t.Run("connection is closed when the test finishes", func(t *testing.T) {
+		t.Parallel()
+		mockT := util.MockTestingT{}
+		returned := make(chan struct{})
+		tester := New(&mockT, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer close(returned)
+			conn, rw, err := upgradeWebSocket(w, r)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			_, _ = websocket.ReadFrame(rw)
+		}))
+		tester.Get("/ws")
+		ws := tester.ExecuteWebSocket()
+		tester.AssertStatusCode(http.StatusSwitchingProtocols)
+
+		mockT.RunCleanups()
+		select {
+		case <-returned:
+		case <-time.After(time.Second):
+			t.Fatalf("Expected the handler to return once the test finished")
+		}
+		if _, err := http.Get(ws.server.URL); err == nil {
+			t.Errorf("Expected the local server to be closed")
+		}
+	})
+}